@@ -2,131 +2,505 @@ package accountclient
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"path"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/go-openapi/strfmt"
+	"github.com/localhost418/accountclient/auth"
+	"github.com/localhost418/accountclient/countryrules"
+	"github.com/localhost418/accountclient/generated/models"
+	"github.com/localhost418/accountclient/signing"
 	"github.com/localhost418/accountclient/types"
 )
 
 const (
-	baseAPIPath     = "v1"
-	accountsAPIPath = baseAPIPath + "/organisation/accounts"
+	baseAPIPath          = "v1"
+	accountsResourcePath = "organisation/accounts"
 )
 
 // Client implements account service
 type Client struct {
-	client *http.Client
-	url    url.URL
+	client         *http.Client
+	url            url.URL
+	apiPath        string
+	headers        http.Header
+	userAgent      string
+	organisationID strfmt.UUID
+	retry          *RetryPolicy
+	tokens         auth.TokenSource
+	signer         *signing.Signer
+	validate       bool
+	countryRules   *countryrules.Registry
+	interceptors   []Interceptor
+	hooks          []OperationHook
 }
 
 // NewClient creates a new Client (*http.Client and api URL)
 func NewClient(client *http.Client, url url.URL) *Client {
 	return &Client{
-		client: client,
-		url:    url,
+		client:  client,
+		url:     url,
+		apiPath: baseAPIPath,
 	}
 }
 
+/*
+apiCall describes a single call to the account API.
+operation and request are the name and typed request of the operation given to the hooks and interceptors.
+link, when set, replaces paths and query. conflict maps a 409 response to ErrVersionConflict (versioned operations)
+and idempotent marks the calls which are safe to retry. send sets attempts to the number of attempts made.
+*/
+type apiCall struct {
+	operation  string
+	request    interface{}
+	method     string
+	endpoint   string
+	link       string
+	paths      []string
+	query      url.Values
+	body       io.WriterTo
+	status     int
+	conflict   bool
+	idempotent bool
+	response   io.ReaderFrom
+	attempts   int
+}
+
 // CreateAccount creates an account with the fields declared in the request
-func (c *Client) CreateAccount(req *types.CreateAccountRequest) (*types.CreateAccountResponse, *AccountError) {
+func (c *Client) CreateAccount(req *types.CreateAccountRequest) (*types.CreateAccountResponse, error) {
+	return c.CreateAccountWithContext(context.Background(), req)
+}
+
+// CreateAccountWithContext creates an account with the fields declared in the request, bound to ctx
+func (c *Client) CreateAccountWithContext(ctx context.Context, req *types.CreateAccountRequest) (*types.CreateAccountResponse, error) {
 	const method = http.MethodPost
+	endpoint := c.accountsPath()
 	if req == nil {
-		return nil, NewAccountError(fail(method, accountsAPIPath, ErrNoRequest), -1, nil)
+		return nil, NewAccountError(ErrNoRequest, method, endpoint, 0, nil)
+	}
+	req, violations := applyIban(c.withDefaultOrganisation(req))
+	if len(violations) > 0 {
+		return nil, newValidationError(method, endpoint, violations)
+	}
+	if c.validate {
+		if violations := validateCreate(req, c.organisationID, c.countryRules); len(violations) > 0 {
+			return nil, newValidationError(method, endpoint, violations)
+		}
 	}
 
-	body := &bytes.Buffer{}
-	_, err := req.WriteTo(body)
+	res := &types.CreateAccountResponse{}
+	call := &apiCall{
+		operation: OperationCreateAccount,
+		request:   req,
+		method:    method,
+		endpoint:  endpoint,
+		paths:     []string{endpoint},
+		body:      req,
+		status:    http.StatusCreated,
+		// the API rejects a second POST with the same account ID, so replaying it cannot create a duplicate
+		idempotent: req.Data != nil && req.Data.ID != nil,
+		response:   res,
+	}
+	err := c.send(ctx, call)
+	if err != nil && call.idempotent && call.attempts > 1 && err.StatusCode == http.StatusConflict {
+		return c.replayedCreate(ctx, req.Data, err)
+	}
 	if err != nil {
-		return nil, NewAccountError(fail(method, accountsAPIPath, ErrInvalidBody), -1, &err)
+		return nil, err
 	}
+	return res, nil
+}
 
-	r, err := http.NewRequest(method, buildURL(c.url, []string{accountsAPIPath}), body)
+// replayedCreate resolves the 409 of a retried create, the account being possibly created by an attempt whose response was lost:
+// the existing account is returned if it has the attributes of account, otherwise conflict is
+func (c *Client) replayedCreate(ctx context.Context, account *models.Account, conflict *AccountError) (*types.CreateAccountResponse, error) {
+	fetched, err := c.FetchAccountWithContext(ctx, &types.FetchAccountRequest{AccountID: *account.ID})
+	if err != nil || fetched.Data == nil || account.OrganisationID == nil || !sameAccount(account, fetched.Data) {
+		return nil, conflict
+	}
+	return &types.CreateAccountResponse{Data: fetched.Data, Links: fetched.Links}, nil
+}
+
+// FetchAccount fetch an account by accountID
+func (c *Client) FetchAccount(req *types.FetchAccountRequest) (*types.FetchAccountResponse, error) {
+	return c.FetchAccountWithContext(context.Background(), req)
+}
+
+// FetchAccountWithContext fetch an account by accountID, bound to ctx
+func (c *Client) FetchAccountWithContext(ctx context.Context, req *types.FetchAccountRequest) (*types.FetchAccountResponse, error) {
+	const method = http.MethodGet
+	endpoint := c.accountsPath()
+	if req == nil {
+		return nil, NewAccountError(ErrNoRequest, method, endpoint, 0, nil)
+	}
+
+	res := &types.FetchAccountResponse{}
+	err := c.send(ctx, &apiCall{
+		operation:  OperationFetchAccount,
+		request:    req,
+		method:     method,
+		endpoint:   endpoint,
+		paths:      []string{endpoint, req.AccountID.String()},
+		status:     http.StatusOK,
+		idempotent: true,
+		response:   res,
+	})
 	if err != nil {
-		return nil, NewAccountError(fail(method, accountsAPIPath, ErrInvalidRequest), -1, &err)
+		return nil, err
 	}
-	r.Header.Add("Accept", "application/vnd.api+json")
+	return res, nil
+}
 
-	w, err := c.client.Do(r)
+// DeleteAccount deletes an account by accountID and version
+func (c *Client) DeleteAccount(req *types.DeleteAccountRequest) (*types.DeleteAccountResponse, error) {
+	return c.DeleteAccountWithContext(context.Background(), req)
+}
+
+// DeleteAccountWithContext deletes an account by accountID and version, bound to ctx
+func (c *Client) DeleteAccountWithContext(ctx context.Context, req *types.DeleteAccountRequest) (*types.DeleteAccountResponse, error) {
+	const method = http.MethodDelete
+	endpoint := c.accountsPath()
+	if req == nil {
+		return nil, NewAccountError(ErrNoRequest, method, endpoint, 0, nil)
+	}
+
+	err := c.send(ctx, &apiCall{
+		operation:  OperationDeleteAccount,
+		request:    req,
+		method:     method,
+		endpoint:   endpoint,
+		paths:      []string{endpoint, req.AccountID.String()},
+		query:      url.Values{"version": []string{strconv.Itoa(req.Version)}},
+		status:     http.StatusNoContent,
+		conflict:   true,
+		idempotent: true,
+	})
 	if err != nil {
-		return nil, NewAccountError(fail(method, accountsAPIPath, ErrDoRequest), -1, &err)
+		return nil, err
 	}
-	defer w.Body.Close()
+	return &types.DeleteAccountResponse{}, nil
+}
 
-	status := w.StatusCode
-	if status != http.StatusCreated {
-		return nil, NewAccountError(fail(method, accountsAPIPath, ErrAPIFailure), status, nil)
+// AmendAccount amends the attributes of an account by accountID, provided its version did not change
+func (c *Client) AmendAccount(req *types.AmendAccountRequest) (*types.AmendAccountResponse, error) {
+	return c.AmendAccountWithContext(context.Background(), req)
+}
+
+// AmendAccountWithContext amends the attributes of an account by accountID, provided its version did not change, bound to ctx
+func (c *Client) AmendAccountWithContext(ctx context.Context, req *types.AmendAccountRequest) (*types.AmendAccountResponse, error) {
+	const method = http.MethodPatch
+	endpoint := c.accountsPath()
+	if req == nil {
+		return nil, NewAccountError(ErrNoRequest, method, endpoint, 0, nil)
 	}
-	res := &types.CreateAccountResponse{}
-	_, err = res.ReadFrom(w.Body)
+
+	if c.validate {
+		if violations := validateAmend(req); len(violations) > 0 {
+			return nil, newValidationError(method, endpoint, violations)
+		}
+	}
+
+	res := &types.AmendAccountResponse{}
+	err := c.send(ctx, &apiCall{
+		operation: OperationAmendAccount,
+		request:   req,
+		method:    method,
+		endpoint:  endpoint,
+		paths:     []string{endpoint, req.AccountID.String()},
+		body:      req,
+		status:    http.StatusOK,
+		conflict:  true,
+		response:  res,
+	})
 	if err != nil {
-		return nil, NewAccountError(fail(method, accountsAPIPath, ErrInvalidResponse), status, &err)
+		return nil, err
 	}
 	return res, nil
 }
 
-// FetchAccount fetch an account by accountID
-func (c *Client) FetchAccount(req *types.FetchAccountRequest) (*types.FetchAccountResponse, *AccountError) {
+// ListAccounts lists the accounts matching the request filters, one page at a time
+func (c *Client) ListAccounts(req *types.ListAccountsRequest) (*types.ListAccountsResponse, error) {
+	return c.ListAccountsWithContext(context.Background(), req)
+}
+
+// ListAccountsWithContext lists the accounts matching the request filters, one page at a time, bound to ctx
+func (c *Client) ListAccountsWithContext(ctx context.Context, req *types.ListAccountsRequest) (*types.ListAccountsResponse, error) {
 	const method = http.MethodGet
+	endpoint := c.accountsPath()
 	if req == nil {
-		return nil, NewAccountError(fail(method, accountsAPIPath, ErrNoRequest), -1, nil)
+		return nil, NewAccountError(ErrNoRequest, method, endpoint, 0, nil)
+	}
+	if req.PageNumber < 0 || req.PageSize < 0 || req.PageSize > types.MaxPageSize {
+		err := fmt.Errorf("page number must be positive and page size between 0 and %d", types.MaxPageSize)
+		return nil, NewAccountError(ErrInvalidRequest, method, endpoint, 0, err)
 	}
 
-	r, err := http.NewRequest(method, buildURL(c.url, []string{accountsAPIPath, req.AccountID.String()}), nil)
+	res := &types.ListAccountsResponse{}
+	err := c.send(ctx, &apiCall{
+		operation:  OperationListAccounts,
+		request:    req,
+		method:     method,
+		endpoint:   endpoint,
+		paths:      []string{endpoint},
+		query:      c.listQuery(req),
+		status:     http.StatusOK,
+		idempotent: true,
+		response:   res,
+	})
 	if err != nil {
-		return nil, NewAccountError(fail(method, accountsAPIPath, ErrInvalidRequest), -1, &err)
+		return nil, err
 	}
-	r.Header.Add("Accept", "application/vnd.api+json")
+	return res, nil
+}
 
-	w, err := c.client.Do(r)
-	if err != nil {
-		return nil, NewAccountError(fail(method, accountsAPIPath, ErrDoRequest), -1, &err)
+// listQuery returns the query of the listing of req, filtered on the client organisation when req has no organisation filter
+func (c *Client) listQuery(req *types.ListAccountsRequest) url.Values {
+	query := req.Query()
+	if len(req.OrganisationIDs) == 0 && c.organisationID != "" {
+		query.Set("filter[organisation_id]", c.organisationID.String())
 	}
-	defer w.Body.Close()
+	return query
+}
 
-	status := w.StatusCode
-	if status != http.StatusOK {
-		return nil, NewAccountError(fail(method, accountsAPIPath, ErrAPIFailure), status, nil)
+// send executes call between the start and end of the operation hooks
+func (c *Client) send(ctx context.Context, call *apiCall) *AccountError {
+	ends := make([]func(interface{}, error), len(c.hooks))
+	for i, hook := range c.hooks {
+		ctx, ends[i] = hook(ctx, call.operation, call.request)
 	}
-	res := &types.FetchAccountResponse{}
-	_, err = res.ReadFrom(w.Body)
-	if err != nil {
-		return nil, NewAccountError(fail(method, accountsAPIPath, ErrInvalidResponse), status, &err)
+	accErr := c.attempt(ctx, call)
+	for i := len(ends) - 1; i >= 0; i-- {
+		switch {
+		case ends[i] == nil:
+		case accErr != nil:
+			ends[i](nil, accErr)
+		default:
+			ends[i](call.response, nil)
+		}
 	}
-
-	return res, nil
+	return accErr
 }
 
-// DeleteAccount deletes an account by accountID and version
-func (c *Client) DeleteAccount(req *types.DeleteAccountRequest) (*types.DeleteAccountResponse, *AccountError) {
-	const method = http.MethodDelete
-	if req == nil {
-		return nil, NewAccountError(fail(method, accountsAPIPath, ErrNoRequest), -1, nil)
+// attempt builds the HTTP request for call, executes it (retrying according to the retry policy), checks the status code and decodes the response body
+func (c *Client) attempt(ctx context.Context, call *apiCall) *AccountError {
+	var body []byte
+	if call.body != nil {
+		buf := &bytes.Buffer{}
+		_, err := call.body.WriteTo(buf)
+		if err != nil {
+			return NewAccountError(ErrInvalidBody, call.method, call.endpoint, 0, err)
+		}
+		body = buf.Bytes()
+	}
+
+	u := buildURL(c.url, call.paths)
+	if call.link != "" {
+		l, err := c.resolveLink(call.link)
+		if err != nil {
+			return NewAccountError(ErrInvalidRequest, call.method, call.endpoint, 0, err)
+		}
+		u = l
 	}
 
-	r, err := http.NewRequest(method, buildURL(c.url, []string{accountsAPIPath, req.AccountID.String()}), nil)
+	for attempt := 1; ; attempt++ {
+		call.attempts = attempt
+		accErr, retryAfter := c.do(ctx, call, u, body, attempt)
+		delay, retry := c.retry.backoff(call, attempt, accErr, retryAfter)
+		if !retry {
+			return accErr
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return NewAccountError(ErrCancelled, call.method, call.endpoint, 0, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// do executes a single attempt of call through the interceptors, it also returns the delay requested by a Retry-After response header
+func (c *Client) do(ctx context.Context, call *apiCall, u string, body []byte, attempt int) (*AccountError, time.Duration) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	r, err := http.NewRequestWithContext(ctx, call.method, u, reader)
 	if err != nil {
-		return nil, NewAccountError(fail(method, accountsAPIPath, ErrInvalidRequest), -1, &err)
+		return NewAccountError(ErrInvalidRequest, call.method, call.endpoint, 0, err), 0
+	}
+	for k, v := range c.headers {
+		r.Header[k] = append([]string(nil), v...)
+	}
+	r.Header.Set("Accept", "application/vnd.api+json")
+	if c.userAgent != "" {
+		r.Header.Set("User-Agent", c.userAgent)
+	}
+	if call.query != nil {
+		r.URL.RawQuery = call.query.Encode()
+	}
+	if c.tokens != nil {
+		token, err := c.tokens.Token(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return NewAccountError(ErrCancelled, call.method, call.endpoint, 0, ctx.Err()), 0
+			}
+			return NewAccountError(ErrAuthentication, call.method, call.endpoint, 0, err), 0
+		}
+		r.Header.Set("Authorization", token.AuthorizationHeader())
+	}
+
+	inv := &Invocation{Operation: call.operation, Request: call.request, HTTPRequest: r, Attempt: attempt}
+	w, err := c.intercept(inv, func(inv *Invocation) (*http.Response, error) {
+		return c.roundTrip(ctx, call, inv, body)
+	})
+	var accErr *AccountError
+	switch {
+	case err != nil && errors.As(err, &accErr):
+	case err != nil && ctx.Err() != nil:
+		accErr = NewAccountError(ErrCancelled, call.method, call.endpoint, 0, ctx.Err())
+	case err != nil:
+		accErr = NewAccountError(ErrDoRequest, call.method, call.endpoint, 0, err)
+	case w == nil:
+		accErr = NewAccountError(ErrDoRequest, call.method, call.endpoint, 0, errors.New("no response"))
+	case w == inv.response:
+		// the error of the API response stands even if an interceptor dropped it, the response was not decoded
+		accErr = inv.failure
+	case w != inv.response:
+		// a response made by an interceptor
+		if w.Body == nil {
+			w.Body = http.NoBody
+		}
+		defer w.Body.Close()
+		accErr = c.check(call, w)
+	}
+	if accErr == nil {
+		return nil, 0
 	}
+	if w == nil {
+		return accErr, 0
+	}
+	return accErr, parseRetryAfter(w.Header.Get("Retry-After"))
+}
 
-	r.Header.Add("Accept", "application/vnd.api+json")
-	q := r.URL.Query()
-	q.Add("version", strconv.Itoa(req.Version))
-	r.URL.RawQuery = q.Encode()
+// roundTrip signs and sends the HTTP request of inv, then checks the response and decodes its body
+func (c *Client) roundTrip(ctx context.Context, call *apiCall, inv *Invocation, body []byte) (*http.Response, error) {
+	r := inv.HTTPRequest
+	if c.signer != nil {
+		// signed last, so the signature covers the final headers
+		if err := c.signer.Sign(r, body); err != nil {
+			return nil, NewAccountError(ErrInvalidRequest, call.method, call.endpoint, 0, err)
+		}
+	}
 
 	w, err := c.client.Do(r)
 	if err != nil {
-		return nil, NewAccountError(fail(method, accountsAPIPath, ErrDoRequest), -1, &err)
+		// the context error is more meaningful than the transport one when the caller gave up
+		if ctx.Err() != nil {
+			return nil, NewAccountError(ErrCancelled, call.method, call.endpoint, 0, ctx.Err())
+		}
+		return nil, NewAccountError(ErrDoRequest, call.method, call.endpoint, 0, err)
 	}
 	defer w.Body.Close()
+	inv.response = w
 
+	// reset on success, an interceptor may call next again after a failure
+	inv.failure = c.check(call, w)
+	if inv.failure != nil {
+		return w, inv.failure
+	}
+	return w, nil
+}
+
+// check checks the status code of the response w to call and decodes its body into the call response
+func (c *Client) check(call *apiCall, w *http.Response) *AccountError {
 	status := w.StatusCode
-	if status != http.StatusNoContent {
-		return nil, NewAccountError(fail(method, accountsAPIPath, ErrAPIFailure), status, nil)
+	if status != call.status {
+		kind := ErrAPIFailure
+		switch {
+		case status == http.StatusConflict && call.conflict:
+			kind = ErrVersionConflict
+		case status == http.StatusUnauthorized && c.tokens != nil:
+			// the token was revoked or expired early, make sure the next call gets a new one
+			if i, ok := c.tokens.(invalidator); ok {
+				i.Invalidate()
+			}
+		}
+		accErr := NewAccountError(kind, call.method, call.endpoint, status, nil)
+		accErr.readAPIError(w.Body)
+		return accErr
 	}
-	return &types.DeleteAccountResponse{}, nil
+	if call.response == nil {
+		return nil
+	}
+	if _, err := call.response.ReadFrom(w.Body); err != nil {
+		return NewAccountError(ErrInvalidResponse, call.method, call.endpoint, status, err)
+	}
+	return nil
+}
+
+// invalidator is implemented by the token sources caching their token (auth.ClientCredentials)
+type invalidator interface {
+	Invalidate()
+}
+
+// OrganisationID returns the organisation set with WithOrganisationID, empty when none
+func (c *Client) OrganisationID() strfmt.UUID {
+	return c.organisationID
+}
+
+// withDefaultOrganisation returns a copy of req with the client organisation when req has none, req otherwise
+func (c *Client) withDefaultOrganisation(req *types.CreateAccountRequest) *types.CreateAccountRequest {
+	if req.Data == nil || req.Data.OrganisationID != nil || c.organisationID == "" {
+		return req
+	}
+	// copy so the caller request is left untouched
+	data := *req.Data
+	organisationID := c.organisationID
+	data.OrganisationID = &organisationID
+	copied := *req
+	copied.Data = &data
+	return &copied
+}
+
+// accountsPath returns the path of the account resources, under the API version prefix
+func (c *Client) accountsPath() string {
+	return path.Join(c.apiPath, accountsResourcePath)
+}
+
+/*
+resolveLink resolves a link returned by the API (e.g. links.next) against the client base URL.
+The API does not know the path prefix of a gateway in front of it, so an absolute path is rebased under the base path,
+and the scheme and host are always the base ones so the authorization is never sent elsewhere.
+*/
+func (c *Client) resolveLink(link string) (string, error) {
+	l, err := url.Parse(link)
+	if err != nil {
+		return "", err
+	}
+	u := c.url
+	prefix := strings.TrimSuffix(c.url.Path, "/")
+	switch {
+	case !strings.HasPrefix(l.Path, "/"):
+		u.Path = c.url.ResolveReference(&url.URL{Path: l.Path}).Path
+	case prefix != "" && l.Path != prefix && !strings.HasPrefix(l.Path, prefix+"/"):
+		u.Path = prefix + l.Path
+	default:
+		u.Path = l.Path
+	}
+	u.RawPath = ""
+	u.RawQuery = l.RawQuery
+	u.Fragment = ""
+	return u.String(), nil
 }
 
 // buildURL appends paths segments to url
@@ -136,8 +510,3 @@ func buildURL(url url.URL, paths []string) string {
 	}
 	return url.String()
 }
-
-// generic error formating
-func fail(method, endpoint string, msg string) string {
-	return fmt.Sprintf("'%s %s': %s", method, endpoint, msg)
-}
@@ -1,7 +1,10 @@
 package accountclient_test
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -45,17 +48,17 @@ func TestClientCreateRequest(t *testing.T) {
 		req  *types.CreateAccountRequest
 		res  *types.CreateAccountResponse
 		url  url.URL
-		msg  string
+		err  error
 	}{
 		{
 			name: "nil request",
 			req:  nil,
-			msg:  accountclient.ErrNoRequest,
+			err:  accountclient.ErrNoRequest,
 		},
 		{
 			name: "error do request",
 			req:  &types.CreateAccountRequest{},
-			msg:  accountclient.ErrDoRequest,
+			err:  accountclient.ErrDoRequest,
 		},
 	}
 
@@ -64,12 +67,8 @@ func TestClientCreateRequest(t *testing.T) {
 			cli := accountclient.NewClient(&http.Client{Timeout: time.Second}, *fakeURL)
 
 			_, err := cli.CreateAccount(tc.req)
-			if err == nil {
-				t.Fatalf("no error found, expected %s", tc.msg)
-			}
-			message := err.Message
-			if !strings.Contains(message, tc.msg) {
-				t.Fatalf("'%s' not found in '%s'", tc.msg, message)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("unexpected error %v ; expected %v", err, tc.err)
 			}
 		})
 	}
@@ -82,17 +81,17 @@ func TestClientFetchRequest(t *testing.T) {
 		name string
 		req  *types.FetchAccountRequest
 		res  *types.FetchAccountResponse
-		msg  string
+		err  error
 	}{
 		{
 			name: "nil request",
 			req:  nil,
-			msg:  accountclient.ErrNoRequest,
+			err:  accountclient.ErrNoRequest,
 		},
 		{
 			name: "error do request",
 			req:  &types.FetchAccountRequest{},
-			msg:  accountclient.ErrDoRequest,
+			err:  accountclient.ErrDoRequest,
 		},
 	}
 
@@ -101,12 +100,8 @@ func TestClientFetchRequest(t *testing.T) {
 			cli := accountclient.NewClient(&http.Client{Timeout: time.Second}, *fakeURL)
 
 			_, err := cli.FetchAccount(tc.req)
-			if err == nil {
-				t.Fatalf("no error found, expected %s", tc.msg)
-			}
-			message := err.Message
-			if !strings.Contains(message, tc.msg) {
-				t.Fatalf("'%s' not found in '%s'", tc.msg, message)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("unexpected error %v ; expected %v", err, tc.err)
 			}
 		})
 	}
@@ -119,17 +114,17 @@ func TestClientDeleteRequest(t *testing.T) {
 		name string
 		req  *types.DeleteAccountRequest
 		res  *types.DeleteAccountResponse
-		msg  string
+		err  error
 	}{
 		{
 			name: "nil request",
 			req:  nil,
-			msg:  accountclient.ErrNoRequest,
+			err:  accountclient.ErrNoRequest,
 		},
 		{
 			name: "error do request",
 			req:  &types.DeleteAccountRequest{},
-			msg:  accountclient.ErrDoRequest,
+			err:  accountclient.ErrDoRequest,
 		},
 	}
 
@@ -138,12 +133,8 @@ func TestClientDeleteRequest(t *testing.T) {
 			cli := accountclient.NewClient(&http.Client{Timeout: time.Second}, *fakeURL)
 
 			_, err := cli.DeleteAccount(tc.req)
-			if err == nil {
-				t.Fatalf("no error found, expected %s", tc.msg)
-			}
-			message := err.Message
-			if !strings.Contains(message, tc.msg) {
-				t.Fatalf("'%s' not found in '%s'", tc.msg, message)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("unexpected error %v ; expected %v", err, tc.err)
 			}
 		})
 	}
@@ -165,7 +156,7 @@ func TestClientCreateResponse(t *testing.T) {
 		req         *types.CreateAccountRequest
 		status      int
 		res         string
-		err         string
+		err         error
 		expectedRes *types.CreateAccountResponse
 	}{
 		{
@@ -240,10 +231,10 @@ func TestClientCreateResponse(t *testing.T) {
 			cli := accountclient.NewClient(&http.Client{Timeout: time.Second}, *serverURL)
 
 			res, errAcc := cli.CreateAccount(tc.req)
-			if (errAcc == nil) != (tc.err == "") {
-				t.Fatalf("unexpected error %v ; expected %s", errAcc, tc.err)
+			if !errors.Is(errAcc, tc.err) {
+				t.Fatalf("unexpected error %v ; expected %v", errAcc, tc.err)
 			}
-			if tc.err != "" {
+			if tc.err != nil {
 				return
 			}
 
@@ -271,7 +262,7 @@ func TestClientFetchResponse(t *testing.T) {
 		req    *types.FetchAccountRequest
 		status int
 		res    string
-		err    string
+		err    error
 	}{
 		{
 			name:   "invalid status code ",
@@ -313,10 +304,10 @@ func TestClientFetchResponse(t *testing.T) {
 			cli := accountclient.NewClient(&http.Client{Timeout: time.Second}, *serverURL)
 
 			res, errAcc := cli.FetchAccount(tc.req)
-			if (errAcc == nil) != (tc.err == "") {
-				t.Fatalf("unexpected error %v ; expected %s", errAcc, tc.err)
+			if !errors.Is(errAcc, tc.err) {
+				t.Fatalf("unexpected error %v ; expected %v", errAcc, tc.err)
 			}
-			if tc.err != "" {
+			if tc.err != nil {
 				return
 			}
 
@@ -382,7 +373,7 @@ func TestClientDeleteResponse(t *testing.T) {
 		req    *types.DeleteAccountRequest
 		status int
 		res    string
-		err    string
+		err    error
 	}{
 		{
 			name:   "invalid status code ",
@@ -390,6 +381,12 @@ func TestClientDeleteResponse(t *testing.T) {
 			status: http.StatusInternalServerError,
 			err:    accountclient.ErrAPIFailure,
 		},
+		{
+			name:   "version conflict ",
+			req:    &types.DeleteAccountRequest{AccountID: "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", Version: 1},
+			status: http.StatusConflict,
+			err:    accountclient.ErrVersionConflict,
+		},
 		{
 			name:   "response OK ",
 			req:    &types.DeleteAccountRequest{AccountID: "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc"},
@@ -416,10 +413,10 @@ func TestClientDeleteResponse(t *testing.T) {
 			cli := accountclient.NewClient(&http.Client{Timeout: time.Second}, *serverURL)
 
 			res, errAcc := cli.DeleteAccount(tc.req)
-			if (errAcc == nil) != (tc.err == "") {
-				t.Fatalf("unexpected error %v ; expected %s", errAcc, tc.err)
+			if !errors.Is(errAcc, tc.err) {
+				t.Fatalf("unexpected error %v ; expected %v", errAcc, tc.err)
 			}
-			if tc.err != "" {
+			if tc.err != nil {
 				return
 			}
 
@@ -430,14 +427,378 @@ func TestClientDeleteResponse(t *testing.T) {
 	}
 }
 
+func TestClientAmendRequest(t *testing.T) {
+	fakeURL, _ := url.Parse("")
+	tt := []struct {
+		name string
+		req  *types.AmendAccountRequest
+		err  error
+	}{
+		{
+			name: "nil request",
+			req:  nil,
+			err:  accountclient.ErrNoRequest,
+		},
+		{
+			name: "error do request",
+			req:  &types.AmendAccountRequest{},
+			err:  accountclient.ErrDoRequest,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			cli := accountclient.NewClient(&http.Client{Timeout: time.Second}, *fakeURL)
+
+			_, err := cli.AmendAccount(tc.req)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("unexpected error %v ; expected %v", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestClientAmendResponse(t *testing.T) {
+	accountID := strfmt.UUID("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+	country := "GB"
+	tt := []struct {
+		name   string
+		req    *types.AmendAccountRequest
+		status int
+		res    string
+		err    error
+	}{
+		{
+			name:   "invalid status code ",
+			req:    &types.AmendAccountRequest{AccountID: accountID},
+			status: http.StatusInternalServerError,
+			err:    accountclient.ErrAPIFailure,
+		},
+		{
+			name:   "version conflict ",
+			req:    &types.AmendAccountRequest{AccountID: accountID, Version: 3},
+			status: http.StatusConflict,
+			res:    `{"error_message":"invalid version"}`,
+			err:    accountclient.ErrVersionConflict,
+		},
+		{
+			name:   "invalid json ",
+			req:    &types.AmendAccountRequest{AccountID: accountID},
+			status: http.StatusOK,
+			res:    `{ "invalid-json': }`,
+			err:    accountclient.ErrInvalidResponse,
+		},
+		{
+			name: "response OK",
+			req: &types.AmendAccountRequest{
+				AccountID:  accountID,
+				Version:    1,
+				Attributes: &models.AccountAttributes{Country: &country, Name: []string{"new name"}, Status: "confirmed"},
+			},
+			status: http.StatusOK,
+			res:    `{"data":{"attributes":{"country":"GB","name":["new name"],"status":"confirmed"},"id":"ad27e265-9605-4b4b-a0e5-3003ea9cc4dc","organisation_id":"eb0bd6f5-c3f5-44b2-b677-acd23cdde73c","type":"accounts","version":2},"links":{"self":"/v1/organisation/accounts/ad27e265-9605-4b4b-a0e5-3003ea9cc4dc"}}`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				defer r.Body.Close()
+				if r.Method != http.MethodPatch || r.URL.Path != "/v1/organisation/accounts/"+accountID.String() {
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+				}
+				body := struct {
+					Data struct {
+						ID      strfmt.UUID `json:"id"`
+						Type    string      `json:"type"`
+						Version int         `json:"version"`
+					} `json:"data"`
+				}{}
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("cannot decode request body: %s", err)
+				}
+				if body.Data.ID != tc.req.AccountID || body.Data.Type != "accounts" || body.Data.Version != tc.req.Version {
+					t.Errorf("wrong request body %+v", body.Data)
+				}
+				w.WriteHeader(tc.status)
+				w.Write([]byte(tc.res))
+			})
+
+			srv := httptest.NewServer(handler)
+			defer srv.Close()
+
+			serverURL, err := url.Parse(srv.URL)
+			if err != nil {
+				t.Fatalf("cannot build server url: %s", err)
+			}
+
+			cli := accountclient.NewClient(&http.Client{Timeout: time.Second}, *serverURL)
+
+			res, errAcc := cli.AmendAccount(tc.req)
+			if !errors.Is(errAcc, tc.err) {
+				t.Fatalf("unexpected error %v ; expected %v", errAcc, tc.err)
+			}
+			if tc.err != nil {
+				return
+			}
+
+			if res == nil || res.Data == nil {
+				t.Fatal("unexpected nil response")
+			}
+			if *res.Data.Version != 2 || res.Data.Attributes.Status != "confirmed" {
+				got, _ := json.Marshal(res.Data)
+				t.Fatalf("wrong amend response content:\n got %s", string(got))
+			}
+		})
+	}
+}
+
+func TestClientAmendBody(t *testing.T) {
+	accountID := strfmt.UUID("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+	country := "GB"
+	tt := []struct {
+		name string
+		req  *types.AmendAccountRequest
+		body string
+	}{
+		{
+			name: "partial amend",
+			req:  &types.AmendAccountRequest{AccountID: accountID, Version: 1, Attributes: &models.AccountAttributes{Bic: "NWBKGB22"}},
+			body: `{"data":{"id":"ad27e265-9605-4b4b-a0e5-3003ea9cc4dc","type":"accounts","version":1,"attributes":{"bic":"NWBKGB22"}}}`,
+		},
+		{
+			name: "cleared list",
+			req: &types.AmendAccountRequest{
+				AccountID:  accountID,
+				Version:    2,
+				Attributes: &models.AccountAttributes{Country: &country, AlternativeNames: []string{}},
+			},
+			body: `{"data":{"id":"ad27e265-9605-4b4b-a0e5-3003ea9cc4dc","type":"accounts","version":2,"attributes":{"alternative_names":[],"country":"GB"}}}`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				if err != nil {
+					t.Errorf("cannot read request body: %s", err)
+				}
+				if got := strings.TrimSpace(string(body)); got != tc.body {
+					t.Errorf("wrong request body: want\n%s\ngot\n%s", tc.body, got)
+				}
+				w.Write([]byte(`{"data":{"id":"ad27e265-9605-4b4b-a0e5-3003ea9cc4dc","type":"accounts","version":2}}`))
+			})
+			srv := httptest.NewServer(handler)
+			defer srv.Close()
+
+			cli, err := accountclient.NewClientWithOptions(srv.URL)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if _, err := cli.AmendAccount(tc.req); err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func TestClientListRequest(t *testing.T) {
+	fakeURL, _ := url.Parse("")
+	tt := []struct {
+		name string
+		req  *types.ListAccountsRequest
+		err  error
+	}{
+		{
+			name: "nil request",
+			req:  nil,
+			err:  accountclient.ErrNoRequest,
+		},
+		{
+			name: "page size too large",
+			req:  &types.ListAccountsRequest{PageSize: types.MaxPageSize + 1},
+			err:  accountclient.ErrInvalidRequest,
+		},
+		{
+			name: "negative page number",
+			req:  &types.ListAccountsRequest{PageNumber: -1},
+			err:  accountclient.ErrInvalidRequest,
+		},
+		{
+			name: "error do request",
+			req:  &types.ListAccountsRequest{},
+			err:  accountclient.ErrDoRequest,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			cli := accountclient.NewClient(&http.Client{Timeout: time.Second}, *fakeURL)
+
+			_, err := cli.ListAccounts(tc.req)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("unexpected error %v ; expected %v", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestClientListResponse(t *testing.T) {
+	tt := []struct {
+		name   string
+		req    *types.ListAccountsRequest
+		query  string
+		status int
+		res    string
+		err    error
+		count  int
+	}{
+		{
+			name:   "invalid status code ",
+			req:    &types.ListAccountsRequest{},
+			status: http.StatusInternalServerError,
+			err:    accountclient.ErrAPIFailure,
+		},
+		{
+			name:   "invalid json ",
+			req:    &types.ListAccountsRequest{},
+			status: http.StatusOK,
+			res:    `{ "invalid-json': }`,
+			err:    accountclient.ErrInvalidResponse,
+		},
+		{
+			name: "response OK",
+			req: &types.ListAccountsRequest{
+				PageNumber:      2,
+				PageSize:        2,
+				OrganisationIDs: []strfmt.UUID{"eb0bd6f5-c3f5-44b2-b677-acd23cdde73c"},
+				Countries:       []string{"FR", "GB"},
+				Ibans:           []string{"GB11NWBK40030041426819"},
+			},
+			query:  "filter[country]=FR,GB&filter[iban]=GB11NWBK40030041426819&filter[organisation_id]=eb0bd6f5-c3f5-44b2-b677-acd23cdde73c&page[number]=2&page[size]=2",
+			status: http.StatusOK,
+			res:    `{"data":[{"attributes":{"country":"GB","name":["name1"]},"id":"ad27e265-9605-4b4b-a0e5-3003ea9cc4dc","organisation_id":"eb0bd6f5-c3f5-44b2-b677-acd23cdde73c","type":"accounts","version":0},{"attributes":{"country":"FR","name":["name2"]},"id":"c2a36bd6-3b7d-4b52-8ad5-1d6fbe8a4ba5","organisation_id":"eb0bd6f5-c3f5-44b2-b677-acd23cdde73c","type":"accounts","version":0}],"links":{"first":"/v1/organisation/accounts?page%5Bnumber%5D=first","last":"/v1/organisation/accounts?page%5Bnumber%5D=last","next":"/v1/organisation/accounts?page%5Bnumber%5D=3","prev":"/v1/organisation/accounts?page%5Bnumber%5D=1","self":"/v1/organisation/accounts"}}`,
+			count:  2,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				defer r.Body.Close()
+				query, _ := url.QueryUnescape(r.URL.RawQuery)
+				if query != tc.query {
+					t.Errorf("wrong query:\n want %s \n got %s", tc.query, query)
+				}
+				w.WriteHeader(tc.status)
+				w.Write([]byte(tc.res))
+			})
+
+			srv := httptest.NewServer(handler)
+			defer srv.Close()
+
+			serverURL, err := url.Parse(srv.URL)
+			if err != nil {
+				t.Fatalf("cannot build server url: %s", err)
+			}
+
+			cli := accountclient.NewClient(&http.Client{Timeout: time.Second}, *serverURL)
+
+			res, errAcc := cli.ListAccounts(tc.req)
+			if !errors.Is(errAcc, tc.err) {
+				t.Fatalf("unexpected error %v ; expected %v", errAcc, tc.err)
+			}
+			if tc.err != nil {
+				return
+			}
+
+			if res == nil {
+				t.Fatal("unexpected nil response")
+			}
+			if len(res.Data) != tc.count {
+				t.Fatalf("wrong number of accounts: want %d got %d", tc.count, len(res.Data))
+			}
+			if res.Links == nil || res.Links.Next == nil || *res.Links.Next != "/v1/organisation/accounts?page%5Bnumber%5D=3" {
+				got, _ := json.Marshal(res.Links)
+				t.Fatalf("wrong links in list response.\n got %s", string(got))
+			}
+		})
+	}
+}
+
+func TestClientContextCancelled(t *testing.T) {
+	tt := []struct {
+		name string
+		call func(ctx context.Context, cli *accountclient.Client) error
+	}{
+		{
+			name: "create",
+			call: func(ctx context.Context, cli *accountclient.Client) error {
+				_, err := cli.CreateAccountWithContext(ctx, &types.CreateAccountRequest{})
+				return err
+			},
+		},
+		{
+			name: "fetch",
+			call: func(ctx context.Context, cli *accountclient.Client) error {
+				_, err := cli.FetchAccountWithContext(ctx, &types.FetchAccountRequest{})
+				return err
+			},
+		},
+		{
+			name: "delete",
+			call: func(ctx context.Context, cli *accountclient.Client) error {
+				_, err := cli.DeleteAccountWithContext(ctx, &types.DeleteAccountRequest{})
+				return err
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			done := make(chan struct{})
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				defer r.Body.Close()
+				select {
+				case <-r.Context().Done():
+				case <-done:
+				}
+			})
+
+			srv := httptest.NewServer(handler)
+			defer srv.Close()
+			defer close(done)
+
+			serverURL, err := url.Parse(srv.URL)
+			if err != nil {
+				t.Fatalf("cannot build server url: %s", err)
+			}
+
+			cli := accountclient.NewClient(&http.Client{Timeout: 5 * time.Second}, *serverURL)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			errAcc := tc.call(ctx, cli)
+			if !errors.Is(errAcc, accountclient.ErrCancelled) {
+				t.Fatalf("unexpected error %v ; expected %v", errAcc, accountclient.ErrCancelled)
+			}
+			if !errors.Is(errAcc, context.DeadlineExceeded) {
+				t.Fatalf("unexpected wrapped error %v ; expected %v", errAcc, context.DeadlineExceeded)
+			}
+		})
+	}
+}
+
 // integration tests for request errors
 func TestClientCreateErrorIntegration(t *testing.T) {
 	expectedError := accountclient.ErrAPIFailure
 	cli := accountclient.NewClient(&http.Client{Timeout: time.Second}, *apiURL)
 
 	_, err := cli.CreateAccount(&types.CreateAccountRequest{})
-	if err == nil || (!strings.Contains(err.Message, expectedError)) {
-		t.Fatalf("unexpected error message '%s': expected '%s'", err.Message, expectedError)
+	if !errors.Is(err, expectedError) {
+		t.Fatalf("unexpected error '%v': expected '%v'", err, expectedError)
 	}
 }
 
@@ -445,8 +806,8 @@ func TestClientFetchErrorIntegration(t *testing.T) {
 	expectedError := accountclient.ErrAPIFailure
 	cli := accountclient.NewClient(&http.Client{Timeout: time.Second}, *apiURL)
 	_, err := cli.FetchAccount(&types.FetchAccountRequest{AccountID: "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c"})
-	if err == nil || (!strings.Contains(err.Message, expectedError)) {
-		t.Fatalf("unexpected error message '%s': expected '%s'", err.Message, expectedError)
+	if !errors.Is(err, expectedError) {
+		t.Fatalf("unexpected error '%v': expected '%v'", err, expectedError)
 	}
 }
 
@@ -454,8 +815,8 @@ func TestClientDeleteErrorIntegration(t *testing.T) {
 	expectedError := accountclient.ErrAPIFailure
 	cli := accountclient.NewClient(&http.Client{Timeout: time.Second}, *apiURL)
 	_, err := cli.DeleteAccount(&types.DeleteAccountRequest{AccountID: "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c"})
-	if err == nil || (!strings.Contains(err.Message, expectedError)) {
-		t.Fatalf("unexpected error message '%s': expected '%s'", err.Message, expectedError)
+	if !errors.Is(err, expectedError) {
+		t.Fatalf("unexpected error '%v': expected '%v'", err, expectedError)
 	}
 }
 
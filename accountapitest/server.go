@@ -0,0 +1,381 @@
+/*
+Package accountapitest provides an in-process fake of the account API for tests, so the real Client
+can be exercised without the docker-compose stack (accountapi, postgres and vault).
+*/
+package accountapitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/localhost418/accountclient/generated/models"
+	"github.com/localhost418/accountclient/types"
+)
+
+const (
+	// AccountsPath is the path of the account resources served by the fake
+	AccountsPath = "/v1/organisation/accounts"
+
+	// defaultPageSize is the page size of a list request without page[size]
+	defaultPageSize = 100
+)
+
+/*
+Server is a fake account API serving create, fetch, delete, list and amend on /v1/organisation/accounts with the semantics of the real one:
+409 on duplicate ID, 404 on unknown ID, 409 on version mismatch on delete and amend, version incremented on amend
+(which changes only the attributes in its body), created_on and modified_on stamped on every account.
+*/
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	accounts map[strfmt.UUID]*account
+	ids      []strfmt.UUID
+	now      func() time.Time
+}
+
+// account is a stored account resource
+type account struct {
+	*models.Account
+	CreatedOn  strfmt.DateTime `json:"created_on"`
+	ModifiedOn strfmt.DateTime `json:"modified_on"`
+}
+
+// NewServer starts a new fake account API, it must be closed by the caller
+func NewServer() *Server {
+	s := &Server{
+		accounts: map[strfmt.UUID]*account{},
+		now:      time.Now,
+	}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// Accounts returns a copy of the stored accounts, in creation order
+func (s *Server) Accounts() []*models.Account {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	accounts := make([]*models.Account, 0, len(s.ids))
+	for _, id := range s.ids {
+		accounts = append(accounts, copyAccount(s.accounts[id].Account))
+	}
+	return accounts
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	if r.URL.Path == AccountsPath {
+		switch r.Method {
+		case http.MethodPost:
+			s.create(w, r)
+		case http.MethodGet:
+			s.list(w, r)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, AccountsPath+"/")
+	if id == r.URL.Path || !strfmt.IsUUID(id) {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		s.fetch(w, strfmt.UUID(id))
+	case http.MethodDelete:
+		s.delete(w, r, strfmt.UUID(id))
+	case http.MethodPatch:
+		s.amend(w, r, strfmt.UUID(id))
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (s *Server) create(w http.ResponseWriter, r *http.Request) {
+	req := &types.CreateAccountRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil || req.Data == nil {
+		writeError(w, http.StatusBadRequest, "invalid account creation request")
+		return
+	}
+	data := req.Data
+	if err := data.Validate(strfmt.Default); err != nil {
+		writeError(w, http.StatusBadRequest, "validation failure: "+err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.accounts[*data.ID]; ok {
+		writeError(w, http.StatusConflict, "Account cannot be created as it violates a duplicate constraint")
+		return
+	}
+	version := int64(0)
+	data.Version = &version
+	if data.Type == "" {
+		data.Type = "accounts"
+	}
+	now := strfmt.DateTime(s.now().UTC())
+	a := &account{Account: data, CreatedOn: now, ModifiedOn: now}
+	s.accounts[*data.ID] = a
+	s.ids = append(s.ids, *data.ID)
+
+	writeResource(w, http.StatusCreated, a)
+}
+
+func (s *Server) fetch(w http.ResponseWriter, id strfmt.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.accounts[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("record %s does not exist", id))
+		return
+	}
+	writeResource(w, http.StatusOK, a)
+}
+
+func (s *Server) delete(w http.ResponseWriter, r *http.Request, id strfmt.UUID) {
+	version, err := strconv.ParseInt(r.URL.Query().Get("version"), 10, 64)
+	if err != nil || version < 0 {
+		writeError(w, http.StatusBadRequest, "invalid version number")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.accounts[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("record %s does not exist", id))
+		return
+	}
+	if *a.Version != version {
+		writeError(w, http.StatusConflict, "invalid version")
+		return
+	}
+	delete(s.accounts, id)
+	for i, v := range s.ids {
+		if v == id {
+			s.ids = append(s.ids[:i], s.ids[i+1:]...)
+			break
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) amend(w http.ResponseWriter, r *http.Request, id strfmt.UUID) {
+	body := struct {
+		Data *struct {
+			ID         strfmt.UUID                `json:"id"`
+			Version    *int64                     `json:"version"`
+			Attributes map[string]json.RawMessage `json:"attributes"`
+		} `json:"data"`
+	}{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Data == nil || body.Data.Version == nil || body.Data.Attributes == nil {
+		writeError(w, http.StatusBadRequest, "invalid account amendment request")
+		return
+	}
+	if body.Data.ID != id {
+		writeError(w, http.StatusBadRequest, "id in body does not match the path")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.accounts[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("record %s does not exist", id))
+		return
+	}
+	if *a.Version != *body.Data.Version {
+		writeError(w, http.StatusConflict, "invalid version")
+		return
+	}
+
+	amended := copyAccount(a.Account)
+	attributes, err := mergeAttributes(amended.Attributes, body.Data.Attributes)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid account amendment request")
+		return
+	}
+	amended.Attributes = attributes
+	if err := amended.Validate(strfmt.Default); err != nil {
+		writeError(w, http.StatusBadRequest, "validation failure: "+err.Error())
+		return
+	}
+	version := *a.Version + 1
+	amended.Version = &version
+	a.Account = amended
+	a.ModifiedOn = strfmt.DateTime(s.now().UTC())
+
+	writeResource(w, http.StatusOK, a)
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	number, size := 0, defaultPageSize
+	if v := q.Get("page[number]"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "invalid page number")
+			return
+		}
+		number = n
+	}
+	if v := q.Get("page[size]"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > types.MaxPageSize {
+			writeError(w, http.StatusBadRequest, "invalid page size")
+			return
+		}
+		size = n
+	}
+
+	s.mu.Lock()
+	matching := []*models.Account{}
+	for _, id := range s.ids {
+		a := s.accounts[id]
+		if matches(a.Account, q) {
+			matching = append(matching, copyAccount(a.Account))
+		}
+	}
+	s.mu.Unlock()
+
+	start, end := number*size, (number+1)*size
+	if start > len(matching) {
+		start = len(matching)
+	}
+	if end > len(matching) {
+		end = len(matching)
+	}
+	res := &types.ListAccountsResponse{Data: matching[start:end], Links: &types.AccountCreationResponseLinks{}}
+	last := 0
+	if len(matching) > 0 {
+		last = (len(matching) - 1) / size
+	}
+	res.Links.Self = pageLink(q, number, size)
+	res.Links.First = pageLink(q, 0, size)
+	res.Links.Last = pageLink(q, last, size)
+	if number < last {
+		res.Links.Next = pageLink(q, number+1, size)
+	}
+	if number > 0 {
+		res.Links.Prev = pageLink(q, number-1, size)
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// matches tells whether a passes the csv filter[...] of q
+func matches(a *models.Account, q url.Values) bool {
+	attrs := a.Attributes
+	country := ""
+	if attrs.Country != nil {
+		country = *attrs.Country
+	}
+	fields := map[string]string{
+		"organisation_id": a.OrganisationID.String(),
+		"bank_id_code":    attrs.BankIDCode,
+		"bank_id":         attrs.BankID,
+		"account_number":  attrs.AccountNumber,
+		"country":         country,
+		"customer_id":     attrs.CustomerID,
+		"iban":            attrs.Iban,
+	}
+	for name, value := range fields {
+		filter := q.Get("filter[" + name + "]")
+		if filter == "" {
+			continue
+		}
+		found := false
+		for _, f := range strings.Split(filter, ",") {
+			if f == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// pageLink builds the link to a page of the listing, keeping the filters of q
+func pageLink(q url.Values, number, size int) *string {
+	p := url.Values{}
+	for k, v := range q {
+		p[k] = v
+	}
+	p.Set("page[number]", strconv.Itoa(number))
+	p.Set("page[size]", strconv.Itoa(size))
+	link := AccountsPath + "?" + p.Encode()
+	return &link
+}
+
+// copyAccount deep copies a through JSON so callers never share the stored accounts
+func copyAccount(a *models.Account) *models.Account {
+	b, _ := json.Marshal(a)
+	c := &models.Account{}
+	_ = json.Unmarshal(b, c)
+	return c
+}
+
+// mergeAttributes replaces the attributes of current with the ones of patch, a null removing the attribute
+func mergeAttributes(current *models.AccountAttributes, patch map[string]json.RawMessage) (*models.AccountAttributes, error) {
+	fields := map[string]json.RawMessage{}
+	if current != nil {
+		b, err := json.Marshal(current)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(b, &fields); err != nil {
+			return nil, err
+		}
+	}
+	for name, value := range patch {
+		if string(value) == "null" {
+			delete(fields, name)
+			continue
+		}
+		fields[name] = value
+	}
+
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	merged := &models.AccountAttributes{}
+	return merged, json.Unmarshal(b, merged)
+}
+
+// writeResource writes a single account resource with its self link
+func writeResource(w http.ResponseWriter, status int, a *account) {
+	self := AccountsPath + "/" + a.ID.String()
+	writeJSON(w, status, struct {
+		Data  *account                            `json:"data"`
+		Links *types.AccountCreationResponseLinks `json:"links"`
+	}{
+		Data:  a,
+		Links: &types.AccountCreationResponseLinks{Self: &self},
+	})
+}
+
+// writeError writes an ApiError body
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, &types.APIErrorResponse{ErrorMessage: msg})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
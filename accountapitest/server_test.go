@@ -0,0 +1,246 @@
+package accountapitest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	"github.com/localhost418/accountclient"
+	"github.com/localhost418/accountclient/accountapitest"
+	"github.com/localhost418/accountclient/generated/models"
+	"github.com/localhost418/accountclient/types"
+)
+
+const organisationID = strfmt.UUID("eb0bd6f5-c3f5-44b2-b677-acd23cdde73c")
+
+// newAccount returns a valid account model with the given id and country
+func newAccount(id strfmt.UUID, country string) *models.Account {
+	return &models.Account{
+		ID:             &id,
+		OrganisationID: func() *strfmt.UUID { o := organisationID; return &o }(),
+		Type:           "accounts",
+		Attributes: &models.AccountAttributes{
+			Country: &country,
+			Name:    []string{"Jane Doe"},
+		},
+	}
+}
+
+func TestServer(t *testing.T) {
+	srv := accountapitest.NewServer()
+	defer srv.Close()
+
+	cli, err := accountclient.NewClientWithOptions(srv.URL, accountclient.WithTimeout(time.Second))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	accountID := strfmt.UUID("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+	unknownID := strfmt.UUID("c2a36bd6-3b7d-4b52-8ad5-1d6fbe8a4ba5")
+
+	// create
+	created, err := cli.CreateAccount(&types.CreateAccountRequest{Data: newAccount(accountID, "GB")})
+	if err != nil {
+		t.Fatalf("unexpected create error: %s", err)
+	}
+	if *created.Data.Version != 0 || *created.Links.Self != accountapitest.AccountsPath+"/"+accountID.String() {
+		t.Fatalf("wrong create response %+v", created.Data)
+	}
+	_, err = cli.CreateAccount(&types.CreateAccountRequest{Data: newAccount(accountID, "GB")})
+	var accErr *accountclient.AccountError
+	if !errors.As(err, &accErr) || accErr.StatusCode != http.StatusConflict {
+		t.Fatalf("unexpected duplicate create error %v", err)
+	}
+	_, err = cli.CreateAccount(&types.CreateAccountRequest{Data: &models.Account{ID: &unknownID}})
+	if !errors.As(err, &accErr) || accErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("unexpected invalid create error %v", err)
+	}
+
+	// fetch
+	fetched, err := cli.FetchAccount(&types.FetchAccountRequest{AccountID: accountID})
+	if err != nil || *fetched.Data.ID != accountID {
+		t.Fatalf("unexpected fetch response %v (%v)", fetched, err)
+	}
+	_, err = cli.FetchAccount(&types.FetchAccountRequest{AccountID: unknownID})
+	if !errors.As(err, &accErr) || accErr.StatusCode != http.StatusNotFound || accErr.APIErrorMessage == "" {
+		t.Fatalf("unexpected unknown fetch error %v", err)
+	}
+
+	// amend
+	attrs := fetched.Data.Attributes
+	attrs.Name = []string{"Jane Smith"}
+	amended, err := cli.AmendAccount(&types.AmendAccountRequest{AccountID: accountID, Version: 0, Attributes: attrs})
+	if err != nil || *amended.Data.Version != 1 || amended.Data.Attributes.Name[0] != "Jane Smith" {
+		t.Fatalf("unexpected amend response %v (%v)", amended, err)
+	}
+	_, err = cli.AmendAccount(&types.AmendAccountRequest{AccountID: accountID, Version: 0, Attributes: attrs})
+	if !errors.Is(err, accountclient.ErrVersionConflict) {
+		t.Fatalf("unexpected error %v ; expected %v", err, accountclient.ErrVersionConflict)
+	}
+	_, err = cli.AmendAccount(&types.AmendAccountRequest{AccountID: unknownID, Version: 0, Attributes: attrs})
+	if !errors.As(err, &accErr) || accErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("unexpected unknown amend error %v", err)
+	}
+
+	// delete
+	_, err = cli.DeleteAccount(&types.DeleteAccountRequest{AccountID: accountID, Version: 0})
+	if !errors.Is(err, accountclient.ErrVersionConflict) {
+		t.Fatalf("unexpected error %v ; expected %v", err, accountclient.ErrVersionConflict)
+	}
+	_, err = cli.DeleteAccount(&types.DeleteAccountRequest{AccountID: unknownID, Version: 0})
+	if !errors.As(err, &accErr) || accErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("unexpected unknown delete error %v", err)
+	}
+	if _, err := cli.DeleteAccount(&types.DeleteAccountRequest{AccountID: accountID, Version: 1}); err != nil {
+		t.Fatalf("unexpected delete error: %s", err)
+	}
+	_, err = cli.FetchAccount(&types.FetchAccountRequest{AccountID: accountID})
+	if !errors.As(err, &accErr) || accErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("unexpected fetch error after delete %v", err)
+	}
+	if len(srv.Accounts()) != 0 {
+		t.Fatalf("unexpected accounts left %v", srv.Accounts())
+	}
+}
+
+func TestServerList(t *testing.T) {
+	srv := accountapitest.NewServer()
+	defer srv.Close()
+
+	cli, err := accountclient.NewClientWithOptions(srv.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for i := 0; i < 7; i++ {
+		country := "GB"
+		if i%2 == 1 {
+			country = "FR"
+		}
+		id := strfmt.UUID(fmt.Sprintf("ad27e265-9605-4b4b-a0e5-%012d", i))
+		if _, err := cli.CreateAccount(&types.CreateAccountRequest{Data: newAccount(id, country)}); err != nil {
+			t.Fatalf("unexpected create error: %s", err)
+		}
+	}
+
+	tt := []struct {
+		name  string
+		req   *types.ListAccountsRequest
+		count int
+	}{
+		{
+			name:  "every account",
+			req:   &types.ListAccountsRequest{PageSize: 2},
+			count: 7,
+		},
+		{
+			name:  "country filter",
+			req:   &types.ListAccountsRequest{PageSize: 2, Countries: []string{"FR"}},
+			count: 3,
+		},
+		{
+			name:  "several countries",
+			req:   &types.ListAccountsRequest{PageSize: 3, Countries: []string{"FR", "GB"}, OrganisationIDs: []strfmt.UUID{organisationID}},
+			count: 7,
+		},
+		{
+			name:  "no match",
+			req:   &types.ListAccountsRequest{OrganisationIDs: []strfmt.UUID{"c2a36bd6-3b7d-4b52-8ad5-1d6fbe8a4ba5"}},
+			count: 0,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			it := cli.ListAccountsIterator(context.Background(), tc.req, nil)
+			count := 0
+			for it.Next() {
+				count++
+			}
+			if err := it.Err(); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if count != tc.count {
+				t.Fatalf("wrong number of accounts: want %d got %d", tc.count, count)
+			}
+		})
+	}
+
+	res, err := cli.ListAccounts(&types.ListAccountsRequest{PageNumber: 1, PageSize: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(res.Data) != 3 || res.Links.Prev == nil || res.Links.Next == nil || res.Links.First == nil || res.Links.Last == nil {
+		t.Fatalf("wrong page: %d accounts, links %+v", len(res.Data), res.Links)
+	}
+}
+
+func TestServerAmend(t *testing.T) {
+	tt := []struct {
+		name       string
+		attributes string
+		status     int
+		want       *models.AccountAttributes
+	}{
+		{
+			name:       "partial amend",
+			attributes: `{"bic":"NWBKGB22"}`,
+			status:     http.StatusOK,
+			want:       &models.AccountAttributes{Country: swag.String("GB"), Name: []string{"Jane Doe"}, Bic: "NWBKGB22"},
+		},
+		{
+			name:       "cleared attribute",
+			attributes: `{"name":null,"alternative_names":["J. Doe"]}`,
+			status:     http.StatusOK,
+			want:       &models.AccountAttributes{Country: swag.String("GB"), AlternativeNames: []string{"J. Doe"}},
+		},
+		{
+			name:       "null required attribute",
+			attributes: `{"country":null}`,
+			status:     http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := accountapitest.NewServer()
+			defer srv.Close()
+
+			cli, err := accountclient.NewClientWithOptions(srv.URL)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			accountID := strfmt.UUID("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+			if _, err := cli.CreateAccount(&types.CreateAccountRequest{Data: newAccount(accountID, "GB")}); err != nil {
+				t.Fatalf("unexpected create error: %s", err)
+			}
+
+			body := `{"data":{"id":"` + accountID.String() + `","type":"accounts","version":0,"attributes":` + tc.attributes + `}}`
+			req, _ := http.NewRequest(http.MethodPatch, srv.URL+accountapitest.AccountsPath+"/"+accountID.String(), strings.NewReader(body))
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			res.Body.Close()
+			if res.StatusCode != tc.status {
+				t.Fatalf("wrong status: want %d got %d", tc.status, res.StatusCode)
+			}
+
+			got := srv.Accounts()[0].Attributes
+			if tc.want == nil {
+				// a rejected amendment leaves the account untouched
+				tc.want = newAccount(accountID, "GB").Attributes
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("wrong attributes: want %+v got %+v", tc.want, got)
+			}
+		})
+	}
+}
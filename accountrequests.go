@@ -0,0 +1,208 @@
+package accountclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/localhost418/accountclient/types"
+)
+
+const (
+	// accountRequestsResourcePath is the path of the account requests under the API version
+	accountRequestsResourcePath = "organisation/accountrequests"
+
+	accountRequestType           = "account_requests"
+	accountRequestSubmissionType = "account_request_submissions"
+)
+
+// AccountRequestsClient requests the opening of accounts through the scheme (account requests and their submissions).
+// It sends its requests with the Client it is made from, so with the same headers, authentication, signing and retry policy.
+type AccountRequestsClient struct {
+	scheme *schemeResource
+}
+
+// NewAccountRequestsClient creates an AccountRequestsClient sending its requests with c
+func NewAccountRequestsClient(c *Client) *AccountRequestsClient {
+	a := &AccountRequestsClient{}
+	a.scheme = &schemeResource{
+		client:       c,
+		resourcePath: accountRequestsResourcePath,
+		operations: schemeOperations{
+			create:           OperationCreateAccountRequest,
+			fetch:            OperationFetchAccountRequest,
+			createSubmission: OperationCreateAccountRequestSubmission,
+			fetchSubmission:  OperationFetchAccountRequestSubmission,
+		},
+		calls: schemeCalls{
+			create: func(ctx context.Context, req interface{}) (interface{}, error) {
+				res, err := a.Create(ctx, req.(*types.CreateAccountRequestRequest))
+				if err != nil {
+					return nil, err
+				}
+				return res.Data, nil
+			},
+			createSubmission: func(ctx context.Context, id, organisationID strfmt.UUID) (interface{}, error) {
+				res, err := a.CreateSubmission(ctx, &types.CreateAccountRequestSubmissionRequest{
+					AccountRequestID: id,
+					Data:             &types.AccountRequestSubmission{OrganisationID: organisationID},
+				})
+				if err != nil {
+					return nil, err
+				}
+				return res.Data, nil
+			},
+			fetchSubmission: func(ctx context.Context, id, submissionID strfmt.UUID) (interface{}, error) {
+				res, err := a.FetchSubmission(ctx, &types.FetchAccountRequestSubmissionRequest{AccountRequestID: id, SubmissionID: submissionID})
+				if err != nil {
+					return nil, err
+				}
+				return res.Data, nil
+			},
+		},
+	}
+	return a
+}
+
+// AccountRequestOutcome is the result of an account request submitted to the scheme, Status, Reason and Confirmed report its submission
+type AccountRequestOutcome struct {
+	Request    *types.AccountRequest
+	Submission *types.AccountRequestSubmission
+	submissionOutcome
+}
+
+// AccountID returns the ID of the opened account when the submission links to it
+func (o *AccountRequestOutcome) AccountID() strfmt.UUID {
+	if o.Submission == nil || o.Submission.Relationships == nil || o.Submission.Relationships.Account == nil ||
+		len(o.Submission.Relationships.Account.Data) == 0 {
+		return ""
+	}
+	return o.Submission.Relationships.Account.Data[0].ID
+}
+
+// Create creates an account request, the ID, organisation ID and type are defaulted when not set
+func (a *AccountRequestsClient) Create(ctx context.Context, req *types.CreateAccountRequestRequest) (*types.AccountRequestResponse, error) {
+	if req == nil {
+		return nil, a.scheme.noRequest(http.MethodPost)
+	}
+	var id strfmt.UUID
+	if req.Data != nil {
+		// copy so the caller request is left untouched
+		data := *req.Data
+		if err := a.scheme.defaults(&data.ID, &data.OrganisationID, &data.Type, accountRequestType); err != nil {
+			return nil, err
+		}
+		req = &types.CreateAccountRequestRequest{Data: &data}
+		id = data.ID
+	}
+
+	res := &types.AccountRequestResponse{}
+	if err := a.scheme.create(ctx, req, id, &types.FetchAccountRequestRequest{AccountRequestID: id}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Fetch fetches an account request by accountRequestID
+func (a *AccountRequestsClient) Fetch(ctx context.Context, req *types.FetchAccountRequestRequest) (*types.AccountRequestResponse, error) {
+	if req == nil {
+		return nil, a.scheme.noRequest(http.MethodGet)
+	}
+	res := &types.AccountRequestResponse{}
+	if err := a.scheme.fetch(ctx, req, req.AccountRequestID, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// List lists a page of the account requests matching the request filters
+func (a *AccountRequestsClient) List(ctx context.Context, req *types.ListAccountRequestsRequest) (*types.ListAccountRequestsResponse, error) {
+	const method = http.MethodGet
+	endpoint := a.scheme.path()
+	if req == nil {
+		return nil, a.scheme.noRequest(method)
+	}
+	if req.PageNumber < 0 || req.PageSize < 0 || req.PageSize > types.MaxPageSize {
+		err := fmt.Errorf("page number must be positive and page size between 0 and %d", types.MaxPageSize)
+		return nil, NewAccountError(ErrInvalidRequest, method, endpoint, 0, err)
+	}
+
+	query := req.Query()
+	if req.OrganisationID == "" && a.scheme.client.organisationID != "" {
+		query.Set("filter[organisation_id]", a.scheme.client.organisationID.String())
+	}
+
+	res := &types.ListAccountRequestsResponse{}
+	err := a.scheme.client.send(ctx, &apiCall{
+		operation:  OperationListAccountRequests,
+		request:    req,
+		method:     method,
+		endpoint:   endpoint,
+		paths:      []string{endpoint},
+		query:      query,
+		status:     http.StatusOK,
+		idempotent: true,
+		response:   res,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// CreateSubmission submits an account request to the scheme, the ID, organisation ID and type are defaulted when not set
+func (a *AccountRequestsClient) CreateSubmission(ctx context.Context, req *types.CreateAccountRequestSubmissionRequest) (*types.AccountRequestSubmissionResponse, error) {
+	if req == nil {
+		return nil, a.scheme.noRequest(http.MethodPost)
+	}
+	// copy so the caller request is left untouched
+	var data types.AccountRequestSubmission
+	if req.Data != nil {
+		data = *req.Data
+	}
+	if err := a.scheme.defaults(&data.ID, &data.OrganisationID, &data.Type, accountRequestSubmissionType); err != nil {
+		return nil, err
+	}
+	req = &types.CreateAccountRequestSubmissionRequest{AccountRequestID: req.AccountRequestID, Data: &data}
+
+	res := &types.AccountRequestSubmissionResponse{}
+	fetch := &types.FetchAccountRequestSubmissionRequest{AccountRequestID: req.AccountRequestID, SubmissionID: data.ID}
+	if err := a.scheme.createSubmission(ctx, req, req.AccountRequestID, data.ID, fetch, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// FetchSubmission fetches the submission of an account request by accountRequestID and submissionID
+func (a *AccountRequestsClient) FetchSubmission(ctx context.Context, req *types.FetchAccountRequestSubmissionRequest) (*types.AccountRequestSubmissionResponse, error) {
+	if req == nil {
+		return nil, a.scheme.noRequest(http.MethodGet)
+	}
+	res := &types.AccountRequestSubmissionResponse{}
+	if err := a.scheme.fetchSubmission(ctx, req, req.AccountRequestID, req.SubmissionID, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// PollSubmission fetches the submission of an account request until its status is terminal (opts may be nil),
+// it returns the last fetched submission, even on error
+func (a *AccountRequestsClient) PollSubmission(ctx context.Context, req *types.FetchAccountRequestSubmissionRequest, opts *PollOptions) (*types.AccountRequestSubmission, error) {
+	if req == nil {
+		return nil, a.scheme.noRequest(http.MethodGet)
+	}
+	polled, err := a.scheme.pollSubmission(ctx, req.AccountRequestID, req.SubmissionID, opts)
+	submission, _ := polled.(*types.AccountRequestSubmission)
+	return submission, err
+}
+
+// Submit creates an account request, submits it and polls the submission until its status is terminal (opts may be nil).
+// A failed delivery is not an error, see AccountRequestOutcome.Confirmed. On error the outcome holds what was created so far.
+func (a *AccountRequestsClient) Submit(ctx context.Context, req *types.CreateAccountRequestRequest, opts *PollOptions) (*AccountRequestOutcome, error) {
+	outcome := &AccountRequestOutcome{}
+	resource, submission, err := a.scheme.submit(ctx, req, opts, &outcome.submissionOutcome)
+	outcome.Request, _ = resource.(*types.AccountRequest)
+	outcome.Submission, _ = submission.(*types.AccountRequestSubmission)
+	return outcome, err
+}
@@ -0,0 +1,150 @@
+package accountclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/localhost418/accountclient"
+	"github.com/localhost418/accountclient/types"
+)
+
+func TestAccountRequestsSubmit(t *testing.T) {
+	accountID := strfmt.UUID("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+	organisationID := "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c"
+	stub := &submissionStub{
+		prefix:    "/v1/organisation/accountrequests",
+		statuses:  []types.SubmissionStatus{types.SubmissionAccepted, types.SubmissionSubmitted, types.SubmissionDeliveryConfirmed},
+		accountID: accountID.String(),
+	}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	var operations []string
+	record := func(inv *accountclient.Invocation, next accountclient.Handler) (*http.Response, error) {
+		operations = append(operations, inv.Operation)
+		return next(inv)
+	}
+	cli, err := accountclient.NewClientWithOptions(server.URL, accountclient.WithOrganisationID(organisationID), accountclient.WithInterceptors(record))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	requests := accountclient.NewAccountRequestsClient(cli)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	req := &types.CreateAccountRequestRequest{Data: &types.AccountRequest{
+		Attributes: &types.AccountRequestAttributes{Country: "GB", BaseCurrency: "GBP", Bic: "NWBKGB22", Name: []string{"Jane Doe"}},
+	}}
+	outcome, err := requests.Submit(ctx, req, &accountclient.PollOptions{Interval: time.Millisecond})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !outcome.Confirmed() || outcome.AccountID() != accountID {
+		t.Fatalf("wrong outcome: status %s account %s", outcome.Status(), outcome.AccountID())
+	}
+	want := []string{
+		accountclient.OperationCreateAccountRequest,
+		accountclient.OperationCreateAccountRequestSubmission,
+		accountclient.OperationFetchAccountRequestSubmission,
+		accountclient.OperationFetchAccountRequestSubmission,
+	}
+	if !reflect.DeepEqual(operations, want) {
+		t.Fatalf("wrong operations: want %v got %v", want, operations)
+	}
+
+	request := outcome.Request
+	if !strfmt.IsUUID(request.ID.String()) || request.OrganisationID.String() != organisationID || request.Type != "account_requests" {
+		t.Fatalf("wrong account request defaults %+v", request)
+	}
+	created, ok := stub.created["/v1/organisation/accountrequests/"+request.ID.String()+"/submissions"]
+	if !ok {
+		t.Fatalf("submission not created under the account request")
+	}
+	submission := &types.AccountRequestSubmissionResponse{}
+	if err := json.Unmarshal(created, submission); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if submission.Data.Type != "account_request_submissions" || submission.Data.OrganisationID.String() != organisationID {
+		t.Fatalf("wrong submission defaults %+v", submission.Data)
+	}
+}
+
+func TestAccountRequestsList(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the first call fails to check the client retry policy applies
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.Header.Get("X-Request-Source") != "onboarding" {
+			t.Errorf("client header not sent")
+		}
+		want := "filter%5Borganisation_id%5D=eb0bd6f5-c3f5-44b2-b677-acd23cdde73c&filter%5Bsubmission.status%5D=delivery_failed&page%5Bsize%5D=10"
+		if r.URL.Path != "/v1/organisation/accountrequests" || r.URL.RawQuery != want {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		_, _ = w.Write([]byte(`{"data":[{"id":"7d31e653-741e-479d-a15d-44b90cfa146f","type":"account_requests","attributes":{"country":"GB"}}]}`))
+	}))
+	defer server.Close()
+
+	cli, err := accountclient.NewClientWithOptions(server.URL,
+		accountclient.WithOrganisationID("eb0bd6f5-c3f5-44b2-b677-acd23cdde73c"),
+		accountclient.WithHeader("X-Request-Source", "onboarding"),
+		accountclient.WithRetryPolicy(&accountclient.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	requests := accountclient.NewAccountRequestsClient(cli)
+
+	res, err := requests.List(context.Background(), &types.ListAccountRequestsRequest{PageSize: 10, SubmissionStatus: types.SubmissionDeliveryFailed})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(res.Data) != 1 || res.Data[0].Attributes.Country != "GB" {
+		t.Fatalf("wrong account requests %v", res.Data)
+	}
+
+	_, err = requests.List(context.Background(), &types.ListAccountRequestsRequest{PageSize: types.MaxPageSize + 1})
+	if !errors.Is(err, accountclient.ErrInvalidRequest) {
+		t.Fatalf("unexpected error %v ; expected %v", err, accountclient.ErrInvalidRequest)
+	}
+}
+
+func TestAccountRequestsRequest(t *testing.T) {
+	cli, err := accountclient.NewClientWithOptions("http://localhost:8080")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	requests := accountclient.NewAccountRequestsClient(cli)
+	ctx := context.Background()
+	tt := []struct {
+		name string
+		call func() error
+	}{
+		{name: "create", call: func() error { _, err := requests.Create(ctx, nil); return err }},
+		{name: "fetch", call: func() error { _, err := requests.Fetch(ctx, nil); return err }},
+		{name: "list", call: func() error { _, err := requests.List(ctx, nil); return err }},
+		{name: "create submission", call: func() error { _, err := requests.CreateSubmission(ctx, nil); return err }},
+		{name: "fetch submission", call: func() error { _, err := requests.FetchSubmission(ctx, nil); return err }},
+		{name: "poll submission", call: func() error { _, err := requests.PollSubmission(ctx, nil, nil); return err }},
+		{name: "submit", call: func() error { _, err := requests.Submit(ctx, nil, nil); return err }},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.call(); !errors.Is(err, accountclient.ErrNoRequest) {
+				t.Fatalf("unexpected error %v ; expected %v", err, accountclient.ErrNoRequest)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package accountclient
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/localhost418/accountclient/types"
+)
+
+const (
+	// accountAmendmentsResourcePath is the path of the account amendments under the API version
+	accountAmendmentsResourcePath = "organisation/accountamendments"
+
+	accountAmendmentType           = "account_amendments"
+	accountAmendmentSubmissionType = "account_amendment_submissions"
+)
+
+// AccountAmendmentsClient changes accounts asynchronously through the scheme (account amendments and their submissions).
+// It sends its requests with the Client it is made from, so with the same headers, authentication, signing and retry policy.
+type AccountAmendmentsClient struct {
+	scheme *schemeResource
+}
+
+// NewAccountAmendmentsClient creates an AccountAmendmentsClient sending its requests with c
+func NewAccountAmendmentsClient(c *Client) *AccountAmendmentsClient {
+	a := &AccountAmendmentsClient{}
+	a.scheme = &schemeResource{
+		client:       c,
+		resourcePath: accountAmendmentsResourcePath,
+		operations: schemeOperations{
+			create:           OperationCreateAmendment,
+			fetch:            OperationFetchAmendment,
+			createSubmission: OperationCreateAmendmentSubmission,
+			fetchSubmission:  OperationFetchAmendmentSubmission,
+		},
+		calls: schemeCalls{
+			create: func(ctx context.Context, req interface{}) (interface{}, error) {
+				res, err := a.Create(ctx, req.(*types.CreateAccountAmendmentRequest))
+				if err != nil {
+					return nil, err
+				}
+				return res.Data, nil
+			},
+			createSubmission: func(ctx context.Context, id, organisationID strfmt.UUID) (interface{}, error) {
+				res, err := a.CreateSubmission(ctx, &types.CreateAccountAmendmentSubmissionRequest{
+					AmendmentID: id,
+					Data:        &types.AccountAmendmentSubmission{OrganisationID: organisationID},
+				})
+				if err != nil {
+					return nil, err
+				}
+				return res.Data, nil
+			},
+			fetchSubmission: func(ctx context.Context, id, submissionID strfmt.UUID) (interface{}, error) {
+				res, err := a.FetchSubmission(ctx, &types.FetchAccountAmendmentSubmissionRequest{AmendmentID: id, SubmissionID: submissionID})
+				if err != nil {
+					return nil, err
+				}
+				return res.Data, nil
+			},
+		},
+	}
+	return a
+}
+
+// AmendmentOutcome is the result of an account amendment submitted to the scheme, Status, Reason and Confirmed report its submission
+type AmendmentOutcome struct {
+	Amendment  *types.AccountAmendment
+	Submission *types.AccountAmendmentSubmission
+	submissionOutcome
+}
+
+// Create creates an account amendment, the ID, organisation ID and type are defaulted when not set
+func (a *AccountAmendmentsClient) Create(ctx context.Context, req *types.CreateAccountAmendmentRequest) (*types.AccountAmendmentResponse, error) {
+	if req == nil {
+		return nil, a.scheme.noRequest(http.MethodPost)
+	}
+	var id strfmt.UUID
+	if req.Data != nil {
+		// copy so the caller request is left untouched
+		data := *req.Data
+		if err := a.scheme.defaults(&data.ID, &data.OrganisationID, &data.Type, accountAmendmentType); err != nil {
+			return nil, err
+		}
+		req = &types.CreateAccountAmendmentRequest{Data: &data}
+		id = data.ID
+	}
+
+	res := &types.AccountAmendmentResponse{}
+	if err := a.scheme.create(ctx, req, id, &types.FetchAccountAmendmentRequest{AmendmentID: id}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Fetch fetches an account amendment by amendmentID
+func (a *AccountAmendmentsClient) Fetch(ctx context.Context, req *types.FetchAccountAmendmentRequest) (*types.AccountAmendmentResponse, error) {
+	if req == nil {
+		return nil, a.scheme.noRequest(http.MethodGet)
+	}
+	res := &types.AccountAmendmentResponse{}
+	if err := a.scheme.fetch(ctx, req, req.AmendmentID, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// CreateSubmission submits an account amendment to the scheme, the ID, organisation ID and type are defaulted when not set
+func (a *AccountAmendmentsClient) CreateSubmission(ctx context.Context, req *types.CreateAccountAmendmentSubmissionRequest) (*types.AccountAmendmentSubmissionResponse, error) {
+	if req == nil {
+		return nil, a.scheme.noRequest(http.MethodPost)
+	}
+	// copy so the caller request is left untouched
+	var data types.AccountAmendmentSubmission
+	if req.Data != nil {
+		data = *req.Data
+	}
+	if err := a.scheme.defaults(&data.ID, &data.OrganisationID, &data.Type, accountAmendmentSubmissionType); err != nil {
+		return nil, err
+	}
+	req = &types.CreateAccountAmendmentSubmissionRequest{AmendmentID: req.AmendmentID, Data: &data}
+
+	res := &types.AccountAmendmentSubmissionResponse{}
+	fetch := &types.FetchAccountAmendmentSubmissionRequest{AmendmentID: req.AmendmentID, SubmissionID: data.ID}
+	if err := a.scheme.createSubmission(ctx, req, req.AmendmentID, data.ID, fetch, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// FetchSubmission fetches the submission of an account amendment by amendmentID and submissionID
+func (a *AccountAmendmentsClient) FetchSubmission(ctx context.Context, req *types.FetchAccountAmendmentSubmissionRequest) (*types.AccountAmendmentSubmissionResponse, error) {
+	if req == nil {
+		return nil, a.scheme.noRequest(http.MethodGet)
+	}
+	res := &types.AccountAmendmentSubmissionResponse{}
+	if err := a.scheme.fetchSubmission(ctx, req, req.AmendmentID, req.SubmissionID, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// PollSubmission fetches the submission of an account amendment until its status is terminal (opts may be nil),
+// it returns the last fetched submission, even on error
+func (a *AccountAmendmentsClient) PollSubmission(ctx context.Context, req *types.FetchAccountAmendmentSubmissionRequest, opts *PollOptions) (*types.AccountAmendmentSubmission, error) {
+	if req == nil {
+		return nil, a.scheme.noRequest(http.MethodGet)
+	}
+	polled, err := a.scheme.pollSubmission(ctx, req.AmendmentID, req.SubmissionID, opts)
+	submission, _ := polled.(*types.AccountAmendmentSubmission)
+	return submission, err
+}
+
+// Submit creates an account amendment, submits it and polls the submission until its status is terminal (opts may be nil).
+// A failed delivery is not an error, see AmendmentOutcome.Confirmed. On error the outcome holds what was created so far.
+func (a *AccountAmendmentsClient) Submit(ctx context.Context, req *types.CreateAccountAmendmentRequest, opts *PollOptions) (*AmendmentOutcome, error) {
+	outcome := &AmendmentOutcome{}
+	resource, submission, err := a.scheme.submit(ctx, req, opts, &outcome.submissionOutcome)
+	outcome.Amendment, _ = resource.(*types.AccountAmendment)
+	outcome.Submission, _ = submission.(*types.AccountAmendmentSubmission)
+	return outcome, err
+}
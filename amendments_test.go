@@ -0,0 +1,275 @@
+package accountclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/localhost418/accountclient"
+	"github.com/localhost418/accountclient/types"
+)
+
+// submissionStub serves the created ressources and moves each submission through statuses, one per GET
+type submissionStub struct {
+	mu       sync.Mutex
+	prefix   string
+	statuses []types.SubmissionStatus
+	reason   string
+	// accountID is linked to the terminal submissions when set
+	accountID string
+	polls     int
+	created   map[string]json.RawMessage
+}
+
+func (s *submissionStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, s.prefix), "/"), "/")
+
+	switch {
+	case r.Method == http.MethodPost:
+		var body struct {
+			Data map[string]interface{} `json:"data"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if len(segments) == 2 && segments[1] == "submissions" {
+			body.Data["attributes"] = map[string]interface{}{"status": s.statuses[0]}
+		}
+		raw, _ := json.Marshal(body)
+		if s.created == nil {
+			s.created = map[string]json.RawMessage{}
+		}
+		s.created[r.URL.Path] = raw
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write(raw)
+	case len(segments) == 3 && segments[1] == "submissions":
+		status := s.statuses[len(s.statuses)-1]
+		s.polls++
+		if s.polls < len(s.statuses) {
+			status = s.statuses[s.polls]
+		}
+		attributes := map[string]interface{}{"status": status}
+		data := map[string]interface{}{"id": segments[2], "type": "submissions", "attributes": attributes}
+		if status.Terminal() {
+			attributes["status_reason"] = s.reason
+			if s.accountID != "" {
+				data["relationships"] = map[string]interface{}{
+					"account": map[string]interface{}{"data": []interface{}{map[string]interface{}{"id": s.accountID, "type": "accounts"}}},
+				}
+			}
+		}
+		res := map[string]interface{}{"data": data}
+		_ = json.NewEncoder(w).Encode(res)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestAccountAmendmentsSubmit(t *testing.T) {
+	accountID := strfmt.UUID("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+	organisationID := "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c"
+	pending := []types.SubmissionStatus{
+		types.SubmissionAccepted,
+		types.SubmissionValidationPending,
+		types.SubmissionValidationPassed,
+		types.SubmissionReleasedToGateway,
+		types.SubmissionSubmitted,
+	}
+	tt := []struct {
+		name      string
+		statuses  []types.SubmissionStatus
+		reason    string
+		timeout   time.Duration
+		status    types.SubmissionStatus
+		confirmed bool
+		err       error
+	}{
+		{
+			name:      "delivery confirmed",
+			statuses:  append(pending, types.SubmissionDeliveryConfirmed),
+			timeout:   time.Second,
+			status:    types.SubmissionDeliveryConfirmed,
+			confirmed: true,
+		},
+		{
+			name:     "delivery failed",
+			statuses: append(pending, types.SubmissionDeliveryFailed),
+			reason:   "Invalid name",
+			timeout:  time.Second,
+			status:   types.SubmissionDeliveryFailed,
+		},
+		{
+			name:     "deadline",
+			statuses: pending,
+			timeout:  50 * time.Millisecond,
+			status:   types.SubmissionSubmitted,
+			err:      accountclient.ErrCancelled,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			stub := &submissionStub{prefix: "/v1/organisation/accountamendments", statuses: tc.statuses, reason: tc.reason}
+			server := httptest.NewServer(stub)
+			defer server.Close()
+
+			cli, err := accountclient.NewClientWithOptions(server.URL, accountclient.WithOrganisationID(organisationID))
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			amendments := accountclient.NewAccountAmendmentsClient(cli)
+
+			ctx, cancel := context.WithTimeout(context.Background(), tc.timeout)
+			defer cancel()
+			req := &types.CreateAccountAmendmentRequest{Data: &types.AccountAmendment{
+				Attributes: &types.AccountAmendmentAttributes{ModifyReason: "Customer changed name", Name: []string{"Jane Doe"}},
+				Relationships: &types.AccountAmendmentRelationships{Account: &types.AmendmentAccountRelationship{
+					Data: []*types.AmendmentAccountReference{{ID: accountID, Type: "accounts"}},
+				}},
+			}}
+			outcome, err := amendments.Submit(ctx, req, &accountclient.PollOptions{Interval: time.Millisecond, MaxInterval: 5 * time.Millisecond})
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("unexpected error %v ; expected %v", err, tc.err)
+			}
+			if outcome.Status() != tc.status || outcome.Confirmed() != tc.confirmed || outcome.Reason() != tc.reason {
+				t.Fatalf("wrong outcome: status %s confirmed %t reason %q", outcome.Status(), outcome.Confirmed(), outcome.Reason())
+			}
+			if req.Data.ID != "" {
+				t.Fatalf("caller request modified")
+			}
+
+			amendment := outcome.Amendment
+			if !strfmt.IsUUID(amendment.ID.String()) || amendment.OrganisationID.String() != organisationID || amendment.Type != "account_amendments" {
+				t.Fatalf("wrong amendment defaults %+v", amendment)
+			}
+			created, ok := stub.created["/v1/organisation/accountamendments/"+amendment.ID.String()+"/submissions"]
+			if !ok {
+				t.Fatalf("submission not created under the amendment")
+			}
+			submission := &types.AccountAmendmentSubmissionResponse{}
+			if err := json.Unmarshal(created, submission); err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !strfmt.IsUUID(submission.Data.ID.String()) || submission.Data.Type != "account_amendment_submissions" {
+				t.Fatalf("wrong submission defaults %+v", submission.Data)
+			}
+		})
+	}
+}
+
+func TestAccountAmendmentsReplayedSubmit(t *testing.T) {
+	organisationID := "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c"
+	tt := []struct {
+		name string
+		// other stores another ressource than the requested one under its ID
+		other bool
+		err   error
+	}{
+		{name: "created by the first attempts"},
+		{name: "other amendment", other: true, err: accountclient.ErrAPIFailure},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var mu sync.Mutex
+			stored := map[string]map[string]interface{}{}
+			lost := map[string]bool{}
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				if r.Method == http.MethodGet {
+					data, ok := stored[r.URL.Path]
+					if !ok {
+						w.WriteHeader(http.StatusNotFound)
+						return
+					}
+					_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+					return
+				}
+				var body struct {
+					Data map[string]interface{} `json:"data"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				key := r.URL.Path + "/" + body.Data["id"].(string)
+				if _, ok := stored[key]; ok {
+					w.WriteHeader(http.StatusConflict)
+					return
+				}
+				if strings.HasSuffix(r.URL.Path, "/submissions") {
+					body.Data["attributes"] = map[string]interface{}{"status": types.SubmissionDeliveryConfirmed}
+				} else if tc.other {
+					body.Data["attributes"] = map[string]interface{}{"modify_reason": "Other change"}
+				}
+				stored[key] = body.Data
+				// the first attempt of each create lands but its response is lost
+				if !lost[r.URL.Path] {
+					lost[r.URL.Path] = true
+					w.WriteHeader(http.StatusBadGateway)
+					return
+				}
+				w.WriteHeader(http.StatusCreated)
+				_ = json.NewEncoder(w).Encode(body)
+			}))
+			defer srv.Close()
+
+			cli, err := accountclient.NewClientWithOptions(srv.URL,
+				accountclient.WithOrganisationID(organisationID),
+				accountclient.WithRetryPolicy(&accountclient.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}),
+			)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			req := &types.CreateAccountAmendmentRequest{Data: &types.AccountAmendment{
+				Attributes: &types.AccountAmendmentAttributes{ModifyReason: "Customer changed name", Name: []string{"Jane Doe"}},
+			}}
+			outcome, err := accountclient.NewAccountAmendmentsClient(cli).Submit(context.Background(), req, nil)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("unexpected error %v ; expected %v", err, tc.err)
+			}
+			if tc.err == nil && (!outcome.Confirmed() || outcome.Amendment == nil || outcome.Submission == nil) {
+				t.Fatalf("wrong outcome %+v", outcome)
+			}
+		})
+	}
+}
+
+func TestAccountAmendmentsRequest(t *testing.T) {
+	cli, err := accountclient.NewClientWithOptions("http://localhost:8080")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	amendments := accountclient.NewAccountAmendmentsClient(cli)
+	ctx := context.Background()
+	tt := []struct {
+		name string
+		call func() error
+	}{
+		{name: "create", call: func() error { _, err := amendments.Create(ctx, nil); return err }},
+		{name: "fetch", call: func() error { _, err := amendments.Fetch(ctx, nil); return err }},
+		{name: "create submission", call: func() error { _, err := amendments.CreateSubmission(ctx, nil); return err }},
+		{name: "fetch submission", call: func() error { _, err := amendments.FetchSubmission(ctx, nil); return err }},
+		{name: "poll submission", call: func() error { _, err := amendments.PollSubmission(ctx, nil, nil); return err }},
+		{name: "submit", call: func() error { _, err := amendments.Submit(ctx, nil, nil); return err }},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.call(); !errors.Is(err, accountclient.ErrNoRequest) {
+				t.Fatalf("unexpected error %v ; expected %v", err, accountclient.ErrNoRequest)
+			}
+		})
+	}
+}
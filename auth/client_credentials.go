@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultExpiryDelta is how long before its expiry a cached token is refreshed
+	defaultExpiryDelta = 30 * time.Second
+
+	// defaultRefreshTimeout bounds a token request, which no caller context cancels
+	defaultRefreshTimeout = 30 * time.Second
+)
+
+// defaultHTTPClient sends the token requests when no HTTPClient is set
+var defaultHTTPClient = &http.Client{Timeout: defaultRefreshTimeout}
+
+/*
+ClientCredentials is a TokenSource exchanging a client ID and secret for a bearer token on the /oauth2/token endpoint
+(OAuth 2.0 client credentials grant).
+The token is cached until ExpiryDelta before it expires, concurrent callers share a single refresh.
+*/
+type ClientCredentials struct {
+	// TokenURL is the URL of the token endpoint, e.g. https://api.form3.tech/v1/oauth2/token
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+
+	// HTTPClient sends the token requests (a client with a 30s timeout when nil)
+	HTTPClient *http.Client
+
+	// ExpiryDelta is how long before its expiry a token is refreshed (30s when zero)
+	ExpiryDelta time.Duration
+
+	// RefreshTimeout bounds a token request whatever the timeout of HTTPClient (30s when zero)
+	RefreshTimeout time.Duration
+
+	mu       sync.Mutex
+	token    *Token
+	inflight *refresh
+}
+
+// refresh is a token request shared by the callers waiting for it
+type refresh struct {
+	done  chan struct{}
+	token *Token
+	err   error
+}
+
+// tokenResponse is the token endpoint response body (Token definition of the spec)
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+	TokenType   string `json:"token_type"`
+}
+
+// NewClientCredentials creates a ClientCredentials token source (client may be nil)
+func NewClientCredentials(tokenURL, clientID, clientSecret string, client *http.Client) *ClientCredentials {
+	return &ClientCredentials{
+		TokenURL:     tokenURL,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		HTTPClient:   client,
+	}
+}
+
+// Token implements TokenSource, it returns the cached token or waits for a refresh
+func (c *ClientCredentials) Token(ctx context.Context) (*Token, error) {
+	c.mu.Lock()
+	if c.token.valid(time.Now(), c.expiryDelta()) {
+		t := c.token
+		c.mu.Unlock()
+		return t, nil
+	}
+	r := c.inflight
+	if r == nil {
+		r = &refresh{done: make(chan struct{})}
+		c.inflight = r
+		// not bound to ctx: the refresh is shared, a caller giving up must not fail the others, RefreshTimeout bounds it
+		go c.refresh(r)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-r.done:
+		return r.token, r.err
+	}
+}
+
+// Invalidate drops the cached token so the next call to Token requests a new one (e.g. after a 401 response)
+func (c *ClientCredentials) Invalidate() {
+	c.mu.Lock()
+	c.token = nil
+	c.mu.Unlock()
+}
+
+// refresh requests a new token and publishes it to the waiting callers
+func (c *ClientCredentials) refresh(r *refresh) {
+	r.token, r.err = c.requestToken()
+
+	c.mu.Lock()
+	if r.err == nil {
+		c.token = r.token
+	}
+	c.inflight = nil
+	c.mu.Unlock()
+	close(r.done)
+}
+
+// requestToken exchanges the client credentials for a token
+func (c *ClientCredentials) requestToken() (*Token, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.refreshTimeout())
+	defer cancel()
+
+	form := url.Values{"grant_type": []string{"client_credentials"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("invalid token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(c.ClientID), url.QueryEscape(c.ClientSecret))
+
+	client := c.HTTPClient
+	if client == nil {
+		client = defaultHTTPClient
+	}
+	start := time.Now()
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token request failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return nil, fmt.Errorf("token request failed with status %d: %s", res.StatusCode, strings.TrimSpace(string(body)))
+	}
+	tr := &tokenResponse{}
+	if err := json.NewDecoder(res.Body).Decode(tr); err != nil {
+		return nil, fmt.Errorf("invalid token response: %w", err)
+	}
+	if tr.AccessToken == "" {
+		return nil, fmt.Errorf("invalid token response: no access_token")
+	}
+
+	t := &Token{AccessToken: tr.AccessToken, TokenType: tr.TokenType}
+	if tr.ExpiresIn > 0 {
+		// measured from the request start so the token never outlives its real expiry
+		t.Expiry = start.Add(time.Duration(tr.ExpiresIn) * time.Second)
+	}
+	return t, nil
+}
+
+// expiryDelta returns ExpiryDelta or its default
+func (c *ClientCredentials) expiryDelta() time.Duration {
+	if c.ExpiryDelta == 0 {
+		return defaultExpiryDelta
+	}
+	return c.ExpiryDelta
+}
+
+// refreshTimeout returns RefreshTimeout or its default
+func (c *ClientCredentials) refreshTimeout() time.Duration {
+	if c.RefreshTimeout == 0 {
+		return defaultRefreshTimeout
+	}
+	return c.RefreshTimeout
+}
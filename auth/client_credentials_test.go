@@ -0,0 +1,177 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/localhost418/accountclient/auth"
+)
+
+// tokenServer returns a token endpoint issuing tokens valid for expiresIn seconds, after delay
+func tokenServer(t *testing.T, calls *int32, status, expiresIn int, delay time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		n := atomic.AddInt32(calls, 1)
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/oauth2/token" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "client_credentials" {
+			t.Errorf("wrong grant type %v (%v)", r.PostForm, err)
+		}
+		if id, secret, ok := r.BasicAuth(); !ok || id != "client-id" || secret != "client-secret" {
+			t.Errorf("wrong basic auth %s:%s", id, secret)
+		}
+		time.Sleep(delay)
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			w.Write([]byte("Authentication failed"))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":"token-%d","expires_in":%d,"token_type":"Bearer"}`, n, expiresIn)
+	}))
+}
+
+func TestClientCredentials(t *testing.T) {
+	tt := []struct {
+		name        string
+		status      int
+		expiresIn   int
+		expiryDelta time.Duration
+		tokens      []string
+		calls       int32
+		err         bool
+	}{
+		{
+			name:      "token cached",
+			status:    http.StatusOK,
+			expiresIn: 3600,
+			tokens:    []string{"token-1", "token-1", "token-1"},
+			calls:     1,
+		},
+		{
+			name:        "token refreshed before expiry",
+			status:      http.StatusOK,
+			expiresIn:   3600,
+			expiryDelta: time.Hour,
+			tokens:      []string{"token-1", "token-2", "token-3"},
+			calls:       3,
+		},
+		{
+			name:      "token without expiry",
+			status:    http.StatusOK,
+			expiresIn: 0,
+			tokens:    []string{"token-1", "token-1"},
+			calls:     1,
+		},
+		{
+			name:   "authentication failed",
+			status: http.StatusForbidden,
+			tokens: []string{"", ""},
+			calls:  2,
+			err:    true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls int32
+			srv := tokenServer(t, &calls, tc.status, tc.expiresIn, 0)
+			defer srv.Close()
+
+			ts := auth.NewClientCredentials(srv.URL+"/v1/oauth2/token", "client-id", "client-secret", srv.Client())
+			ts.ExpiryDelta = tc.expiryDelta
+
+			for i, want := range tc.tokens {
+				token, err := ts.Token(context.Background())
+				if (err != nil) != tc.err {
+					t.Fatalf("unexpected error %v", err)
+				}
+				if tc.err {
+					continue
+				}
+				if token.AccessToken != want || token.AuthorizationHeader() != "Bearer "+want {
+					t.Fatalf("wrong token %d: want %s got %+v", i, want, token)
+				}
+			}
+			if calls != tc.calls {
+				t.Fatalf("wrong number of token requests: want %d got %d", tc.calls, calls)
+			}
+		})
+	}
+}
+
+func TestClientCredentialsConcurrentRefresh(t *testing.T) {
+	var calls int32
+	srv := tokenServer(t, &calls, http.StatusOK, 3600, 50*time.Millisecond)
+	defer srv.Close()
+
+	ts := auth.NewClientCredentials(srv.URL+"/v1/oauth2/token", "client-id", "client-secret", nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token, err := ts.Token(context.Background())
+			if err != nil || token.AccessToken != "token-1" {
+				t.Errorf("unexpected token %+v (%v)", token, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if calls != 1 {
+		t.Fatalf("wrong number of token requests: want 1 got %d", calls)
+	}
+
+	ts.Invalidate()
+	token, err := ts.Token(context.Background())
+	if err != nil || token.AccessToken != "token-2" {
+		t.Fatalf("token not refreshed after Invalidate: %+v (%v)", token, err)
+	}
+}
+
+func TestClientCredentialsCancelled(t *testing.T) {
+	var calls int32
+	srv := tokenServer(t, &calls, http.StatusOK, 3600, 200*time.Millisecond)
+	defer srv.Close()
+
+	ts := auth.NewClientCredentials(srv.URL+"/v1/oauth2/token", "client-id", "client-secret", nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := ts.Token(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("unexpected error %v ; expected %v", err, context.DeadlineExceeded)
+	}
+
+	// the refresh started by the cancelled caller still serves the next ones
+	token, err := ts.Token(context.Background())
+	if err != nil || token.AccessToken != "token-1" || calls != 1 {
+		t.Fatalf("unexpected token %+v (%v) after %d requests", token, err, calls)
+	}
+}
+
+func TestClientCredentialsRefreshTimeout(t *testing.T) {
+	var calls int32
+	srv := tokenServer(t, &calls, http.StatusOK, 3600, 200*time.Millisecond)
+	defer srv.Close()
+
+	// a client without timeout must not block the shared refresh forever
+	ts := auth.NewClientCredentials(srv.URL+"/v1/oauth2/token", "client-id", "client-secret", &http.Client{})
+	ts.RefreshTimeout = 20 * time.Millisecond
+
+	start := time.Now()
+	if _, err := ts.Token(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected error %v ; expected %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Fatalf("refresh not bounded by RefreshTimeout: took %s", elapsed)
+	}
+}
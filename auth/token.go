@@ -0,0 +1,35 @@
+// Package auth provides the authentication of the requests sent to the account API
+package auth
+
+import (
+	"context"
+	"time"
+)
+
+// Token is an OAuth2 access token
+type Token struct {
+	AccessToken string
+	TokenType   string
+
+	// Expiry is the time the token expires at (zero when it does not expire)
+	Expiry time.Time
+}
+
+// TokenSource provides the token sent in the Authorization header of every request
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+}
+
+// AuthorizationHeader returns the Authorization header value of the token
+func (t *Token) AuthorizationHeader() string {
+	// the API only issues bearer tokens, token_type is sometimes lowercase or missing
+	return "Bearer " + t.AccessToken
+}
+
+// valid tells whether the token can still be used at now, delta before its expiry
+func (t *Token) valid(now time.Time, delta time.Duration) bool {
+	if t == nil || t.AccessToken == "" {
+		return false
+	}
+	return t.Expiry.IsZero() || now.Add(delta).Before(t.Expiry)
+}
@@ -0,0 +1,104 @@
+package bulk
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/localhost418/accountclient/generated/models"
+)
+
+// columns which are not account attributes
+const (
+	columnID             = "id"
+	columnOrganisationID = "organisation_id"
+	columnVersion        = "version"
+	columnKey            = "key"
+)
+
+// column is a file column, path is the JSON path of the attribute (nil for the columns which are not attributes)
+type column struct {
+	name string
+	path []string
+	kind reflect.Kind
+}
+
+// dateType is the type of the date attributes, written as strings
+var dateType = reflect.TypeOf(strfmt.Date{})
+
+// attributeColumn finds the attribute of the column name, a JSON name or a dotted path for the nested attributes
+func attributeColumn(name string) (column, error) {
+	t := reflect.TypeOf(models.AccountAttributes{})
+	path := strings.Split(name, ".")
+	for i, p := range path {
+		f, ok := jsonField(t, p)
+		if !ok {
+			return column{}, fmt.Errorf("unknown column %q", name)
+		}
+		ft := indirect(f.Type)
+		if i < len(path)-1 {
+			if ft.Kind() != reflect.Struct || ft == dateType {
+				return column{}, fmt.Errorf("unknown column %q", name)
+			}
+			t = ft
+			continue
+		}
+		if kind, ok := leafKind(ft); ok {
+			return column{name: name, path: path, kind: kind}, nil
+		}
+	}
+	return column{}, fmt.Errorf("column %q is not supported", name)
+}
+
+// attributeColumns lists the columns of every attribute which fits a cell, nested ones flattened, in the order of the model
+func attributeColumns(t reflect.Type, prefix []string) []column {
+	var columns []column
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		path := append(append([]string{}, prefix...), name)
+		ft := indirect(f.Type)
+		if kind, ok := leafKind(ft); ok {
+			columns = append(columns, column{name: strings.Join(path, "."), path: path, kind: kind})
+		} else if ft.Kind() == reflect.Struct {
+			columns = append(columns, attributeColumns(ft, path)...)
+		}
+	}
+	return columns
+}
+
+// leafKind returns the kind of the value of an attribute of type t: a string, a boolean or a list of strings
+func leafKind(t reflect.Type) (reflect.Kind, bool) {
+	switch {
+	case t.Kind() == reflect.String, t == dateType:
+		return reflect.String, true
+	case t.Kind() == reflect.Bool:
+		return reflect.Bool, true
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.String:
+		return reflect.Slice, true
+	}
+	return reflect.Invalid, false
+}
+
+// indirect returns the type pointed by t, t when it is not a pointer
+func indirect(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		return t.Elem()
+	}
+	return t
+}
+
+// jsonField returns the field of struct t with the JSON name
+func jsonField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if strings.Split(f.Tag.Get("json"), ",")[0] == name {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
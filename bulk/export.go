@@ -0,0 +1,367 @@
+package bulk
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/localhost418/accountclient"
+	"github.com/localhost418/accountclient/generated/models"
+	"github.com/localhost418/accountclient/types"
+)
+
+// DefaultPIIColumns are the columns holding personal data, a nested attribute name covering all its columns
+var DefaultPIIColumns = []string{
+	"account_number",
+	"alternative_bank_account_names",
+	"alternative_names",
+	"bank_account_name",
+	"customer_id",
+	"first_name",
+	"iban",
+	"name",
+	"secondary_identification",
+	"organisation_identification.address",
+	"organisation_identification.identification",
+	"private_identification",
+}
+
+// pseudonymSize is the number of bytes of the HMAC kept in a pseudonym
+const pseudonymSize = 16
+
+// ExportOptions of an Exporter
+type ExportOptions struct {
+	// Columns are the exported columns: id, organisation_id, version and the attributes named as in the import files, every one when empty.
+	// organisation_identification.actors, a list of objects which does not fit a column, is never exported
+	Columns []string
+	// PseudonymKey, when set, replaces the values of the PII columns with their HMAC-SHA256 under the key,
+	// so equal values have equal pseudonyms and the exports can still be reconciled
+	PseudonymKey []byte
+	// PII lists the pseudonymised columns, DefaultPIIColumns when nil
+	PII []string
+	// PageSize is the number of accounts listed per request, the API default when 0
+	PageSize int
+}
+
+// Exporter writes the accounts listed with a Client to CSV or JSON Lines files
+type Exporter struct {
+	client   *accountclient.Client
+	columns  []column
+	pii      []bool
+	key      []byte
+	pageSize int
+}
+
+// NewExporter makes an Exporter listing accounts with client, opts is optional and an unknown column is an error
+func NewExporter(client *accountclient.Client, opts *ExportOptions) (*Exporter, error) {
+	if opts == nil {
+		opts = &ExportOptions{}
+	}
+	ex := &Exporter{client: client, key: opts.PseudonymKey, pageSize: opts.PageSize}
+
+	if len(opts.Columns) == 0 {
+		ex.columns = []column{{name: columnID, kind: reflect.String}, {name: columnOrganisationID, kind: reflect.String}, {name: columnVersion, kind: reflect.Int}}
+		ex.columns = append(ex.columns, attributeColumns(reflect.TypeOf(models.AccountAttributes{}), nil)...)
+	}
+	seen := map[string]bool{}
+	for _, name := range opts.Columns {
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate column %q", name)
+		}
+		seen[name] = true
+		switch name {
+		case columnID, columnOrganisationID:
+			ex.columns = append(ex.columns, column{name: name, kind: reflect.String})
+		case columnVersion:
+			ex.columns = append(ex.columns, column{name: name, kind: reflect.Int})
+		default:
+			col, err := attributeColumn(name)
+			if err != nil {
+				return nil, err
+			}
+			ex.columns = append(ex.columns, col)
+		}
+	}
+
+	pii := opts.PII
+	if pii == nil {
+		pii = DefaultPIIColumns
+	}
+	ex.pii = make([]bool, len(ex.columns))
+	for i, col := range ex.columns {
+		for _, p := range pii {
+			if col.name == p || strings.HasPrefix(col.name, p+".") {
+				ex.pii[i] = len(ex.key) > 0
+			}
+		}
+	}
+	return ex, nil
+}
+
+/*
+Export writes in format (FormatCSV or FormatJSONL) the accounts of the listing of req (every account of the client organisation when nil) to w,
+and returns the number of accounts written.
+The listing is streamed page by page, so the memory used does not grow with the number of accounts.
+CSV files have a header row and the cells of the import files, JSON Lines files have an object per account
+with the attributes of the columns next to id, organisation_id and version (the empty ones left out), as read by JSONLReader.
+Both can be imported back.
+*/
+func (ex *Exporter) Export(ctx context.Context, req *types.ListAccountsRequest, format string, w io.Writer) (int, error) {
+	var rw rowWriter
+	switch format {
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		header := make([]string, len(ex.columns))
+		for i, col := range ex.columns {
+			header[i] = col.name
+		}
+		if err := cw.Write(header); err != nil {
+			return 0, err
+		}
+		rw = &csvRowWriter{w: cw, columns: ex.columns}
+	case FormatJSONL:
+		rw = &jsonlRowWriter{w: bufio.NewWriter(w), columns: ex.columns}
+	default:
+		return 0, fmt.Errorf("unknown format %q", format)
+	}
+
+	// copy so the caller request is left untouched
+	listReq := &types.ListAccountsRequest{}
+	if req != nil {
+		*listReq = *req
+	}
+	if ex.pageSize > 0 {
+		listReq.PageSize = ex.pageSize
+	}
+
+	count := 0
+	it := ex.client.ListAccountsIterator(ctx, listReq, &accountclient.IteratorOptions{Prefetch: true})
+	for it.Next() {
+		values, err := ex.values(it.Account())
+		if err != nil {
+			return count, err
+		}
+		if err := rw.write(values); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := rw.flush(); err != nil {
+		return count, err
+	}
+	return count, it.Err()
+}
+
+// values returns the values of the columns of account: a string, a bool, a []string, an int64 or nil when empty
+func (ex *Exporter) values(account *models.Account) ([]interface{}, error) {
+	attributes := map[string]interface{}{}
+	if account.Attributes != nil {
+		b, err := json.Marshal(account.Attributes)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(b, &attributes); err != nil {
+			return nil, err
+		}
+	}
+
+	values := make([]interface{}, len(ex.columns))
+	for i, col := range ex.columns {
+		var value interface{}
+		switch {
+		case col.name == columnID && account.ID != nil:
+			value = account.ID.String()
+		case col.name == columnOrganisationID && account.OrganisationID != nil:
+			value = account.OrganisationID.String()
+		case col.name == columnVersion && account.Version != nil:
+			value = *account.Version
+		case col.path != nil:
+			value = lookupPath(attributes, col.path)
+		}
+		if ex.pii[i] {
+			value = ex.pseudonymise(value)
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// lookupPath returns the value at path in the nested maps of m as a string, a bool or a []string, nil when there is none
+func lookupPath(m map[string]interface{}, path []string) interface{} {
+	for _, p := range path[:len(path)-1] {
+		next, ok := m[p].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		m = next
+	}
+	switch v := m[path[len(path)-1]].(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+		return v
+	case bool:
+		return v
+	case []interface{}:
+		if len(v) == 0 {
+			return nil
+		}
+		values := make([]string, len(v))
+		for i := range v {
+			values[i] = fmt.Sprint(v[i])
+		}
+		return values
+	}
+	return nil
+}
+
+// pseudonymise replaces the strings of value with their pseudonym
+func (ex *Exporter) pseudonymise(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return ex.pseudonym(v)
+	case []string:
+		values := make([]string, len(v))
+		for i := range v {
+			values[i] = ex.pseudonym(v[i])
+		}
+		return values
+	}
+	return value
+}
+
+// pseudonym returns the hex of the truncated HMAC-SHA256 of s under the pseudonym key
+func (ex *Exporter) pseudonym(s string) string {
+	mac := hmac.New(sha256.New, ex.key)
+	mac.Write([]byte(s))
+	return hex.EncodeToString(mac.Sum(nil)[:pseudonymSize])
+}
+
+// rowWriter writes the column values of an account
+type rowWriter interface {
+	write(values []interface{}) error
+	flush() error
+}
+
+// csvRowWriter writes CSV records, lists joined with ListSeparator
+type csvRowWriter struct {
+	w       *csv.Writer
+	columns []column
+	record  []string
+}
+
+func (cw *csvRowWriter) write(values []interface{}) error {
+	if cw.record == nil {
+		cw.record = make([]string, len(cw.columns))
+	}
+	for i, value := range values {
+		switch v := value.(type) {
+		case nil:
+			cw.record[i] = ""
+		case string:
+			cw.record[i] = v
+		case bool:
+			cw.record[i] = strconv.FormatBool(v)
+		case int64:
+			cw.record[i] = strconv.FormatInt(v, 10)
+		case []string:
+			cw.record[i] = strings.Join(v, ListSeparator)
+		}
+	}
+	return cw.w.Write(cw.record)
+}
+
+func (cw *csvRowWriter) flush() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// jsonlRowWriter writes a JSON object per line, with the keys in the column order and the nested attributes as nested objects
+type jsonlRowWriter struct {
+	w       *bufio.Writer
+	columns []column
+}
+
+func (jw *jsonlRowWriter) write(values []interface{}) error {
+	row := &jsonObject{}
+	for i, value := range values {
+		if value == nil {
+			continue
+		}
+		path := jw.columns[i].path
+		if path == nil {
+			path = []string{jw.columns[i].name}
+		}
+		row.set(path, value)
+	}
+	if err := row.writeTo(jw.w); err != nil {
+		return err
+	}
+	return jw.w.WriteByte('\n')
+}
+
+// jsonObject is a JSON object keeping the order its keys were set in
+type jsonObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// set sets value at path, the parents being nested objects
+func (o *jsonObject) set(path []string, value interface{}) {
+	if o.values == nil {
+		o.values = map[string]interface{}{}
+	}
+	name := path[0]
+	if _, ok := o.values[name]; !ok {
+		o.keys = append(o.keys, name)
+	}
+	if len(path) == 1 {
+		o.values[name] = value
+		return
+	}
+	child, ok := o.values[name].(*jsonObject)
+	if !ok {
+		child = &jsonObject{}
+		o.values[name] = child
+	}
+	child.set(path[1:], value)
+}
+
+// writeTo writes the JSON of the object to w
+func (o *jsonObject) writeTo(w *bufio.Writer) error {
+	w.WriteByte('{')
+	for i, name := range o.keys {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		b, _ := json.Marshal(name)
+		w.Write(b)
+		w.WriteByte(':')
+		if child, ok := o.values[name].(*jsonObject); ok {
+			if err := child.writeTo(w); err != nil {
+				return err
+			}
+			continue
+		}
+		b, err := json.Marshal(o.values[name])
+		if err != nil {
+			return err
+		}
+		w.Write(b)
+	}
+	return w.WriteByte('}')
+}
+
+func (jw *jsonlRowWriter) flush() error {
+	return jw.w.Flush()
+}
@@ -0,0 +1,211 @@
+package bulk_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/localhost418/accountclient"
+	"github.com/localhost418/accountclient/accountapitest"
+	"github.com/localhost418/accountclient/bulk"
+	"github.com/localhost418/accountclient/generated/models"
+	"github.com/localhost418/accountclient/types"
+)
+
+// newExportClient returns a client of a fake API with a GB account of a person and a FR account of an organisation
+func newExportClient(t *testing.T) (*accountclient.Client, *accountapitest.Server) {
+	api := accountapitest.NewServer()
+	t.Cleanup(api.Close)
+	cli, err := accountclient.NewClientWithOptions(api.URL, accountclient.WithOrganisationID(organisationID))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	accounts := []string{
+		`{"id":"ad27e265-9605-4b4b-a0e5-3003ea9cc4dc","country":"GB","bank_id":"400300","bank_id_code":"GBDSC","bic":"NWBKGB22",` +
+			`"name":["Jane Doe","J. Doe"],"joint_account":true,"private_identification":{"city":"London","address":["1 High Street"]}}`,
+		`{"id":"bd27e265-9605-4b4b-a0e5-3003ea9cc4dc","country":"FR","bank_id":"20041","bank_id_code":"FR","bic":"PSSTFRPP",` +
+			`"name":["Jean Dupont"],"organisation_identification":{"identification":"FR123","city":"Paris"}}`,
+	}
+	for _, line := range accounts {
+		row, err := bulk.NewJSONLReader(strings.NewReader(line)).Next()
+		if err != nil || row.Err != nil {
+			t.Fatalf("unexpected error %v %v", err, row.Err)
+		}
+		if _, err := cli.CreateAccount(&types.CreateAccountRequest{Data: row.Account}); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+	return cli, api
+}
+
+// pseudonym is the expected pseudonym of s under key
+func pseudonym(key, s string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(s))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+func TestExport(t *testing.T) {
+	cli, _ := newExportClient(t)
+	tt := []struct {
+		name   string
+		opts   *bulk.ExportOptions
+		req    *types.ListAccountsRequest
+		format string
+		want   string
+	}{
+		{
+			name:   "csv columns",
+			opts:   &bulk.ExportOptions{Columns: []string{"id", "version", "country", "name", "joint_account", "private_identification.city"}},
+			format: bulk.FormatCSV,
+			want: "id,version,country,name,joint_account,private_identification.city\n" +
+				"ad27e265-9605-4b4b-a0e5-3003ea9cc4dc,0,GB,Jane Doe|J. Doe,true,London\n" +
+				"bd27e265-9605-4b4b-a0e5-3003ea9cc4dc,0,FR,Jean Dupont,,\n",
+		},
+		{
+			name:   "jsonl columns",
+			opts:   &bulk.ExportOptions{Columns: []string{"id", "name", "joint_account", "organisation_identification.identification"}},
+			format: bulk.FormatJSONL,
+			want: `{"id":"ad27e265-9605-4b4b-a0e5-3003ea9cc4dc","name":["Jane Doe","J. Doe"],"joint_account":true}` + "\n" +
+				`{"id":"bd27e265-9605-4b4b-a0e5-3003ea9cc4dc","name":["Jean Dupont"],"organisation_identification":{"identification":"FR123"}}` + "\n",
+		},
+		{
+			name:   "filter",
+			opts:   &bulk.ExportOptions{Columns: []string{"id", "bic"}},
+			req:    &types.ListAccountsRequest{Countries: []string{"FR"}},
+			format: bulk.FormatCSV,
+			want:   "id,bic\nbd27e265-9605-4b4b-a0e5-3003ea9cc4dc,PSSTFRPP\n",
+		},
+		{
+			name:   "pseudonymised",
+			opts:   &bulk.ExportOptions{Columns: []string{"bank_id", "name", "private_identification.city"}, PseudonymKey: []byte("secret")},
+			req:    &types.ListAccountsRequest{Countries: []string{"GB"}},
+			format: bulk.FormatCSV,
+			want: "bank_id,name,private_identification.city\n" +
+				"400300," + pseudonym("secret", "Jane Doe") + "|" + pseudonym("secret", "J. Doe") + "," + pseudonym("secret", "London") + "\n",
+		},
+		{
+			name:   "own pii columns",
+			opts:   &bulk.ExportOptions{Columns: []string{"bank_id", "name"}, PseudonymKey: []byte("secret"), PII: []string{"bank_id"}},
+			req:    &types.ListAccountsRequest{Countries: []string{"GB"}},
+			format: bulk.FormatJSONL,
+			want:   `{"bank_id":"` + pseudonym("secret", "400300") + `","name":["Jane Doe","J. Doe"]}` + "\n",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			ex, err := bulk.NewExporter(cli, tc.opts)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			out := &bytes.Buffer{}
+			if _, err := ex.Export(context.Background(), tc.req, tc.format, out); err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if out.String() != tc.want {
+				t.Fatalf("wrong export: want\n%s\ngot\n%s", tc.want, out.String())
+			}
+		})
+	}
+}
+
+func TestExporterColumns(t *testing.T) {
+	tt := []struct {
+		name    string
+		columns []string
+		err     string
+	}{
+		{name: "unknown column", columns: []string{"id", "bank"}, err: `unknown column "bank"`},
+		{name: "key column", columns: []string{"key"}, err: `unknown column "key"`},
+		{name: "object column", columns: []string{"organisation_identification.actors"}, err: `column "organisation_identification.actors" is not supported`},
+		{name: "duplicate column", columns: []string{"id", "id"}, err: `duplicate column "id"`},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := bulk.NewExporter(nil, &bulk.ExportOptions{Columns: tc.columns})
+			if err == nil || err.Error() != tc.err {
+				t.Fatalf("unexpected error %v ; expected %v", err, tc.err)
+			}
+		})
+	}
+}
+
+// TestExportImport imports the default exports, of every column, in another API
+func TestExportImport(t *testing.T) {
+	cli, api := newExportClient(t)
+	ex, err := bulk.NewExporter(cli, nil)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	want := map[string]*models.AccountAttributes{}
+	for _, account := range api.Accounts() {
+		want[account.ID.String()] = account.Attributes
+	}
+
+	for _, format := range []string{bulk.FormatCSV, bulk.FormatJSONL} {
+		t.Run(format, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			if n, err := ex.Export(context.Background(), nil, format, out); err != nil || n != 2 {
+				t.Fatalf("unexpected export of %d accounts (error %v)", n, err)
+			}
+
+			target := accountapitest.NewServer()
+			defer target.Close()
+			targetCli, _ := accountclient.NewClientWithOptions(target.URL, accountclient.WithOrganisationID(organisationID))
+			src, err := bulk.NewReader(format, out)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			summary, err := bulk.NewImporter(targetCli, nil).Import(context.Background(), src, io.Discard)
+			if err != nil || summary.Created != 2 {
+				t.Fatalf("wrong import %+v (error %v)", summary, err)
+			}
+			for _, account := range target.Accounts() {
+				if !reflect.DeepEqual(account.Attributes, want[account.ID.String()]) {
+					t.Fatalf("wrong attributes: want %+v got %+v", want[account.ID.String()], account.Attributes)
+				}
+			}
+		})
+	}
+}
+
+func TestExportPages(t *testing.T) {
+	api := accountapitest.NewServer()
+	defer api.Close()
+	cli, _ := accountclient.NewClientWithOptions(api.URL, accountclient.WithOrganisationID(organisationID))
+	country := "GB"
+	for i := 0; i < 25; i++ {
+		id, _ := accountclient.AccountIDFromKey(organisationID, fmt.Sprint(i))
+		_, err := cli.CreateAccount(&types.CreateAccountRequest{Data: &models.Account{ID: &id, Attributes: &models.AccountAttributes{Country: &country}}})
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+
+	ex, _ := bulk.NewExporter(cli, &bulk.ExportOptions{Columns: []string{"id"}, PageSize: 10})
+	out := &bytes.Buffer{}
+	n, err := ex.Export(context.Background(), nil, bulk.FormatJSONL, out)
+	if err != nil || n != 25 || strings.Count(out.String(), "\n") != 25 {
+		t.Fatalf("wrong export of %d accounts (error %v)", n, err)
+	}
+
+	_, err = ex.Export(context.Background(), nil, "xml", out)
+	if err == nil {
+		t.Fatalf("expected an unknown format error")
+	}
+	// a request rejected by the client is returned
+	_, err = ex.Export(context.Background(), &types.ListAccountsRequest{PageNumber: -1}, bulk.FormatCSV, io.Discard)
+	if !errors.Is(err, accountclient.ErrInvalidRequest) {
+		t.Fatalf("unexpected error %v ; expected %v", err, accountclient.ErrInvalidRequest)
+	}
+}
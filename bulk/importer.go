@@ -0,0 +1,217 @@
+package bulk
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/localhost418/accountclient"
+	"github.com/localhost418/accountclient/generated/models"
+	"github.com/localhost418/accountclient/types"
+)
+
+// defaultWorkers is the number of accounts created concurrently by default
+const defaultWorkers = 4
+
+// Options of an import
+type Options struct {
+	// Workers is the number of accounts created concurrently, 4 when 0
+	Workers int
+	// Skip holds the numbers of the rows already imported (see Completed)
+	Skip map[int]bool
+}
+
+// Summary counts the rows of an import by status, Skipped counts the rows of Options.Skip
+type Summary struct {
+	Created    int
+	Duplicates int
+	Invalid    int
+	Failed     int
+	Skipped    int
+}
+
+// add counts a result
+func (s *Summary) add(res *Result) {
+	switch res.Status {
+	case StatusCreated:
+		s.Created++
+	case StatusDuplicate:
+		s.Duplicates++
+	case StatusInvalid:
+		s.Invalid++
+	case StatusFailed:
+		s.Failed++
+	}
+}
+
+// Importer creates the accounts of the rows of a file with a Client
+type Importer struct {
+	client *accountclient.Client
+	opts   Options
+}
+
+// NewImporter makes an Importer creating accounts with client, opts is optional
+func NewImporter(client *accountclient.Client, opts *Options) *Importer {
+	im := &Importer{client: client}
+	if opts != nil {
+		im.opts = *opts
+	}
+	if im.opts.Workers <= 0 {
+		im.opts.Workers = defaultWorkers
+	}
+	return im
+}
+
+/*
+Import validates and creates the accounts of the rows of src and writes the Result of each row to report
+(a JSON object per line, in the order the rows complete).
+A row without id gets the ID derived from its organisation ID and its key (see accountclient.AccountIDFromKey);
+without key, the key is "sha256:" followed by the hex SHA-256 of the JSON of its account, so the same row always gets the same ID.
+A row is created when the API creates its account.
+A row is duplicate when the API already has an account with its ID and the same attributes, e.g. a row imported again after a crash.
+A row is failed on any other error of the create, including an existing account with its ID and other attributes.
+A row is invalid when it cannot be read or fails the client validation (see Client.ValidateCreateAccount).
+Import stops on a read error of src, a write error of report or when ctx is done, and returns the summary of the rows reported.
+*/
+func (im *Importer) Import(ctx context.Context, src RowReader, report io.Writer) (*Summary, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	rows := make(chan *Row)
+	results := make(chan *Result)
+	var wg sync.WaitGroup
+	for i := 0; i < im.opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for row := range rows {
+				results <- im.importRow(ctx, row)
+			}
+		}()
+	}
+
+	summary := &Summary{}
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(rows)
+		for {
+			if err := ctx.Err(); err != nil {
+				readErr <- err
+				return
+			}
+			row, err := src.Next()
+			if err == io.EOF {
+				readErr <- nil
+				return
+			}
+			if err != nil {
+				readErr <- err
+				return
+			}
+			if im.opts.Skip[row.Number] {
+				// only this goroutine writes Skipped until readErr is received
+				summary.Skipped++
+				continue
+			}
+			select {
+			case rows <- row:
+			case <-ctx.Done():
+				readErr <- ctx.Err()
+				return
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	enc := json.NewEncoder(report)
+	var writeErr error
+	for res := range results {
+		if writeErr != nil {
+			continue
+		}
+		if writeErr = enc.Encode(res); writeErr != nil {
+			cancel()
+			continue
+		}
+		summary.add(res)
+	}
+	err := <-readErr
+	if writeErr != nil {
+		return summary, fmt.Errorf("write report: %w", writeErr)
+	}
+	return summary, err
+}
+
+// importRow validates and creates the account of row
+func (im *Importer) importRow(ctx context.Context, row *Row) *Result {
+	res := &Result{Row: row.Number, Key: row.Key, Status: StatusInvalid}
+	if row.Err != nil {
+		res.Error = newResultError(row.Err)
+		return res
+	}
+
+	// copy so the row is left untouched, key stays empty for a row with an id
+	account := *row.Account
+	var key string
+	if account.ID == nil {
+		organisationID := im.client.OrganisationID()
+		if account.OrganisationID != nil {
+			organisationID = *account.OrganisationID
+		}
+		if organisationID == "" {
+			res.Error = newResultError(errors.New("no organisation id to derive the account id"))
+			return res
+		}
+		key = row.Key
+		if key == "" {
+			var err error
+			if key, err = contentKey(&account); err != nil {
+				res.Error = newResultError(err)
+				return res
+			}
+		}
+		id, err := accountclient.AccountIDFromKey(organisationID, key)
+		if err != nil {
+			res.Error = newResultError(err)
+			return res
+		}
+		account.ID = &id
+	}
+	res.AccountID = *account.ID
+
+	req := &types.CreateAccountRequest{Data: &account}
+	if err := im.client.ValidateCreateAccount(req); err != nil {
+		res.Error = newResultError(err)
+		return res
+	}
+	// a 409 is compared with the existing account, so a row is only a duplicate of the same account
+	created, err := im.client.CreateAccountIdempotentWithContext(ctx, key, req)
+	switch {
+	case err != nil:
+		res.Status = StatusFailed
+		res.Error = newResultError(err)
+	case created.Existing:
+		res.Status = StatusDuplicate
+	default:
+		res.Status = StatusCreated
+	}
+	return res
+}
+
+// contentKey is the business key of a row without key: the hash of its account, so the same row always gets the same ID
+func contentKey(account *models.Account) (string, error) {
+	b, err := json.Marshal(account)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
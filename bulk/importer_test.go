@@ -0,0 +1,234 @@
+package bulk_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/localhost418/accountclient"
+	"github.com/localhost418/accountclient/accountapitest"
+	"github.com/localhost418/accountclient/bulk"
+)
+
+const organisationID = "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c"
+
+// importCSV is a file with 2 valid rows, a duplicate of the first one, an invalid bic, an unreadable row and a row failing on the API side
+const importCSV = "key,country,bank_id,bank_id_code,bic,name\n" +
+	"cust-1,GB,400300,GBDSC,NWBKGB22,Jane Doe\n" +
+	",GB,400301,GBDSC,NWBKGB22,John Doe\n" +
+	"cust-1,GB,400300,GBDSC,NWBKGB22,Jane Doe\n" +
+	"cust-4,GB,400300,GBDSC,NWBK,Bad Bic\n" +
+	"cust-5,GB\n" +
+	"cust-6,GB,500500,GBDSC,NWBKGB22,Server Failure\n"
+
+// newImportServer serves the fake API, failing the creation of the accounts named "Server Failure"
+func newImportServer(t *testing.T) (*httptest.Server, *accountapitest.Server) {
+	api := accountapitest.NewServer()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if bytes.Contains(body, []byte("Server Failure")) {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error_message":"database unavailable"}`))
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		api.ServeHTTP(w, r)
+	}))
+	t.Cleanup(func() {
+		srv.Close()
+		api.Close()
+	})
+	return srv, api
+}
+
+// decodeReport decodes the results of a report, sorted by row
+func decodeReport(t *testing.T, report []byte) []*bulk.Result {
+	var results []*bulk.Result
+	dec := json.NewDecoder(bytes.NewReader(report))
+	for dec.More() {
+		res := &bulk.Result{}
+		if err := dec.Decode(res); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		results = append(results, res)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Row < results[j].Row })
+	return results
+}
+
+func TestImport(t *testing.T) {
+	srv, api := newImportServer(t)
+	cli, err := accountclient.NewClientWithOptions(srv.URL, accountclient.WithOrganisationID(organisationID))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	src, err := bulk.NewCSVReader(strings.NewReader(importCSV))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	report := &bytes.Buffer{}
+	im := bulk.NewImporter(cli, &bulk.Options{Workers: 1})
+	summary, err := im.Import(context.Background(), src, report)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	want := &bulk.Summary{Created: 2, Duplicates: 1, Invalid: 2, Failed: 1}
+	if !reflect.DeepEqual(summary, want) {
+		t.Fatalf("wrong summary: want %+v got %+v", want, summary)
+	}
+	if len(api.Accounts()) != 2 {
+		t.Fatalf("wrong number of accounts: want 2 got %d", len(api.Accounts()))
+	}
+
+	results := decodeReport(t, report.Bytes())
+	statuses := []bulk.Status{}
+	for _, res := range results {
+		statuses = append(statuses, res.Status)
+	}
+	wantStatuses := []bulk.Status{bulk.StatusCreated, bulk.StatusCreated, bulk.StatusDuplicate, bulk.StatusInvalid, bulk.StatusInvalid, bulk.StatusFailed}
+	if !reflect.DeepEqual(statuses, wantStatuses) {
+		t.Fatalf("wrong statuses: want %v got %v", wantStatuses, statuses)
+	}
+
+	keyID, _ := accountclient.AccountIDFromKey(organisationID, "cust-1")
+	if results[0].AccountID != keyID || !strfmt.IsUUID(results[1].AccountID.String()) || results[1].AccountID == keyID || results[2].AccountID != keyID {
+		t.Fatalf("wrong account ids %s %s %s", results[0].AccountID, results[1].AccountID, results[2].AccountID)
+	}
+	wantViolations := []bulk.ResultViolation{{Field: "data.attributes.bic", Message: `attributes.bic in body should match '^([A-Z]{6}[A-Z0-9]{2}|[A-Z]{6}[A-Z0-9]{5})$'`}}
+	if results[3].Error == nil || results[3].Error.Kind != accountclient.ErrValidation.Error() || !reflect.DeepEqual(results[3].Error.Violations, wantViolations) {
+		t.Fatalf("wrong validation error %+v", results[3].Error)
+	}
+	if results[4].Error == nil || !strings.Contains(results[4].Error.Message, "wrong number of fields") {
+		t.Fatalf("wrong read error %+v", results[4].Error)
+	}
+	failed := results[5].Error
+	if failed == nil || failed.Kind != accountclient.ErrAPIFailure.Error() || failed.StatusCode != http.StatusInternalServerError || failed.APIErrorMessage != "database unavailable" {
+		t.Fatalf("wrong api error %+v", failed)
+	}
+}
+
+func TestImportResume(t *testing.T) {
+	srv, api := newImportServer(t)
+	cli, err := accountclient.NewClientWithOptions(srv.URL, accountclient.WithOrganisationID(organisationID))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	im := bulk.NewImporter(cli, nil)
+
+	src, _ := bulk.NewCSVReader(strings.NewReader(importCSV))
+	report := &bytes.Buffer{}
+	if _, err := im.Import(context.Background(), src, report); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// an import stopped while writing the report leaves an incomplete last line
+	report.WriteString(`{"row":6,"sta`)
+	done, err := bulk.Completed(bytes.NewReader(report.Bytes()))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	want := map[int]bool{1: true, 2: true, 3: true}
+	if !reflect.DeepEqual(done, want) {
+		t.Fatalf("wrong completed rows: want %v got %v", want, done)
+	}
+
+	src, _ = bulk.NewCSVReader(strings.NewReader(importCSV))
+	resumed := &bytes.Buffer{}
+	summary, err := bulk.NewImporter(cli, &bulk.Options{Skip: done}).Import(context.Background(), src, resumed)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	wantSummary := &bulk.Summary{Invalid: 2, Failed: 1, Skipped: 3}
+	if !reflect.DeepEqual(summary, wantSummary) {
+		t.Fatalf("wrong summary: want %+v got %+v", wantSummary, summary)
+	}
+	if len(api.Accounts()) != 2 {
+		t.Fatalf("wrong number of accounts: want 2 got %d", len(api.Accounts()))
+	}
+
+	// without the report, the rows already imported are duplicates
+	src, _ = bulk.NewCSVReader(strings.NewReader(importCSV))
+	summary, err = im.Import(context.Background(), src, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if summary.Duplicates != 3 || summary.Created != 0 {
+		t.Fatalf("wrong summary %+v", summary)
+	}
+}
+
+func TestImportDuplicates(t *testing.T) {
+	srv, api := newImportServer(t)
+	cli, err := accountclient.NewClientWithOptions(srv.URL, accountclient.WithOrganisationID(organisationID))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	im := bulk.NewImporter(cli, &bulk.Options{Workers: 1})
+
+	src, _ := bulk.NewCSVReader(strings.NewReader("key,country,bank_id,bank_id_code,bic,name\n" +
+		"cust-1,GB,400300,GBDSC,NWBKGB22,Jane Doe\n" +
+		"cust-1,GB,400300,GBDSC,NWBKGB22,Jane Smith\n" +
+		",GB,400301,GBDSC,NWBKGB22,John Doe\n"))
+	report := &bytes.Buffer{}
+	summary, err := im.Import(context.Background(), src, report)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if want := (&bulk.Summary{Created: 2, Failed: 1}); !reflect.DeepEqual(summary, want) {
+		t.Fatalf("wrong summary: want %+v got %+v", want, summary)
+	}
+	// another account with the key of an existing one is not a duplicate
+	conflict := decodeReport(t, report.Bytes())[1].Error
+	if conflict == nil || conflict.Kind != accountclient.ErrConflictingDuplicate.Error() {
+		t.Fatalf("wrong conflict error %+v", conflict)
+	}
+
+	// the row without key gets the same account ID from another file
+	src2 := bulk.NewJSONLReader(strings.NewReader(`{"country":"GB","bank_id":"400301","bank_id_code":"GBDSC","bic":"NWBKGB22","name":["John Doe"]}` + "\n" +
+		`{"country":"GB","bank_id":"400301","bank_id_code":"GBDSC","bic":"NWBKGB22","name":["John Smith"]}` + "\n"))
+	summary, err = im.Import(context.Background(), src2, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if want := (&bulk.Summary{Created: 1, Duplicates: 1}); !reflect.DeepEqual(summary, want) || len(api.Accounts()) != 3 {
+		t.Fatalf("wrong summary: want %+v got %+v", want, summary)
+	}
+}
+
+func TestCompletedInvalidReport(t *testing.T) {
+	report := `{"row":1,"status":"created"}` + "\n" + "not json\n" + `{"row":2,"status":"created"}` + "\n"
+	if _, err := bulk.Completed(strings.NewReader(report)); err == nil || !strings.Contains(err.Error(), "invalid report line 2") {
+		t.Fatalf("unexpected error %v ; expected an invalid report line error", err)
+	}
+
+	// a row failed then created on resume
+	report = `{"row":1,"status":"failed"}` + "\n" + `{"row":1,"status":"created"}` + "\n" + `{"row":2,"status":"created"}` + "\n" + `{"row":2,"status":"failed"}` + "\n"
+	done, err := bulk.Completed(strings.NewReader(report))
+	if err != nil || !reflect.DeepEqual(done, map[int]bool{1: true}) {
+		t.Fatalf("wrong completed rows %v (error %v)", done, err)
+	}
+}
+
+func TestImportCancelled(t *testing.T) {
+	srv, _ := newImportServer(t)
+	cli, err := accountclient.NewClientWithOptions(srv.URL, accountclient.WithOrganisationID(organisationID))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	src, _ := bulk.NewCSVReader(strings.NewReader(importCSV))
+	if _, err := bulk.NewImporter(cli, nil).Import(ctx, src, io.Discard); err != context.Canceled {
+		t.Fatalf("unexpected error %v ; expected %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,297 @@
+/*
+Package bulk imports accounts in bulk from CSV or JSON Lines files: every row is validated, the accounts are created concurrently
+and the result of each row is written to a report from which an interrupted import can be resumed.
+It also exports the listed accounts to the same formats, streamed page by page.
+*/
+package bulk
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/localhost418/accountclient/generated/models"
+)
+
+const (
+	// FormatCSV is a CSV file with a header row naming the columns
+	FormatCSV = "csv"
+
+	// FormatJSONL is a JSON Lines file with an account, or its attributes, per line
+	FormatJSONL = "jsonl"
+
+	// ListSeparator separates the values of a list attribute (e.g. name) in a CSV cell
+	ListSeparator = "|"
+
+	// maxLineSize caps the size of a JSON Lines row
+	maxLineSize = 1024 * 1024
+)
+
+/*
+Row is an account read from a file.
+Number is the position of the row in the file (the line for JSON Lines, the record after the header for CSV),
+Key the optional business key of the account (see accountclient.AccountIDFromKey) and Err the reason the row could not be read.
+*/
+type Row struct {
+	Number  int
+	Key     string
+	Account *models.Account
+	Err     error
+}
+
+// RowReader reads the rows of a file, Next returns io.EOF after the last row
+type RowReader interface {
+	Next() (*Row, error)
+}
+
+// FormatOf returns the format of a file from its extension (.csv, .jsonl or .ndjson), empty when unknown
+func FormatOf(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return FormatCSV
+	case ".jsonl", ".ndjson":
+		return FormatJSONL
+	}
+	return ""
+}
+
+// NewReader makes the RowReader of format on r
+func NewReader(format string, r io.Reader) (RowReader, error) {
+	switch format {
+	case FormatCSV:
+		return NewCSVReader(r)
+	case FormatJSONL:
+		return NewJSONLReader(r), nil
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+/*
+CSVReader reads the accounts of a CSV file.
+The header names the columns with the JSON name of the attributes, nested ones with a dotted path (e.g. private_identification.first_name).
+The id, organisation_id and key columns hold the account ID, organisation ID and business key, a version column (of the exported files) is ignored.
+Empty cells are left out, list cells are split on ListSeparator and boolean cells are parsed with strconv.ParseBool.
+*/
+type CSVReader struct {
+	r       *csv.Reader
+	columns []column
+	number  int
+}
+
+// NewCSVReader reads the header of the CSV file of r, an unknown column is an error
+func NewCSVReader(r io.Reader) (*CSVReader, error) {
+	cr := &CSVReader{r: csv.NewReader(r)}
+	header, err := cr.r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("invalid csv header: %w", err)
+	}
+
+	seen := map[string]bool{}
+	for i, name := range header {
+		if i == 0 {
+			// spreadsheets often start their exports with a byte order mark
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		name = strings.TrimSpace(name)
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate column %q", name)
+		}
+		seen[name] = true
+		col := column{name: name, kind: reflect.String}
+		if name != columnID && name != columnOrganisationID && name != columnVersion && name != columnKey {
+			if col, err = attributeColumn(name); err != nil {
+				return nil, err
+			}
+		}
+		cr.columns = append(cr.columns, col)
+	}
+	return cr, nil
+}
+
+// Next implements RowReader, a record with the wrong number of cells or malformed quotes is a row with Err set
+func (cr *CSVReader) Next() (*Row, error) {
+	record, err := cr.r.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	cr.number++
+	row := &Row{Number: cr.number}
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		row.Err = err
+		return row, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	row.Account, row.Key, row.Err = cr.account(record)
+	return row, nil
+}
+
+// account maps the cells of record onto an account
+func (cr *CSVReader) account(record []string) (*models.Account, string, error) {
+	account := &models.Account{Type: "accounts"}
+	var key string
+	attributes := map[string]interface{}{}
+	for i, col := range cr.columns {
+		cell := strings.TrimSpace(record[i])
+		if cell == "" {
+			continue
+		}
+		switch col.name {
+		case columnID:
+			id := strfmt.UUID(cell)
+			account.ID = &id
+			continue
+		case columnOrganisationID:
+			id := strfmt.UUID(cell)
+			account.OrganisationID = &id
+			continue
+		case columnKey:
+			key = cell
+			continue
+		case columnVersion:
+			// exported files have the version, which is set by the API
+			continue
+		}
+
+		var value interface{} = cell
+		switch col.kind {
+		case reflect.Bool:
+			b, err := strconv.ParseBool(cell)
+			if err != nil {
+				return nil, key, fmt.Errorf("column %s: invalid boolean %q", col.name, cell)
+			}
+			value = b
+		case reflect.Slice:
+			values := strings.Split(cell, ListSeparator)
+			for j := range values {
+				values[j] = strings.TrimSpace(values[j])
+			}
+			value = values
+		}
+		setPath(attributes, col.path, value)
+	}
+
+	b, err := json.Marshal(attributes)
+	if err != nil {
+		return nil, key, err
+	}
+	account.Attributes = &models.AccountAttributes{}
+	if err := json.Unmarshal(b, account.Attributes); err != nil {
+		return nil, key, fmt.Errorf("invalid attributes: %w", err)
+	}
+	return account, key, nil
+}
+
+// setPath sets value at path in the nested maps of m
+func setPath(m map[string]interface{}, path []string, value interface{}) {
+	for _, p := range path[:len(path)-1] {
+		next, ok := m[p].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			m[p] = next
+		}
+		m = next
+	}
+	m[path[len(path)-1]] = value
+}
+
+/*
+JSONLReader reads the accounts of a JSON Lines file, blank lines are skipped.
+A line is either an account (with attributes) or the attributes of an account next to optional id and organisation_id
+(and version, ignored, as written by Exporter), both with an optional key. Unknown fields are an error.
+*/
+type JSONLReader struct {
+	s      *bufio.Scanner
+	number int
+}
+
+// NewJSONLReader makes the JSONLReader of r
+func NewJSONLReader(r io.Reader) *JSONLReader {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	return &JSONLReader{s: s}
+}
+
+// Next implements RowReader, a line which is not a valid account is a row with Err set
+func (jr *JSONLReader) Next() (*Row, error) {
+	for jr.s.Scan() {
+		jr.number++
+		line := bytes.TrimSpace(jr.s.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		row := &Row{Number: jr.number}
+		row.Account, row.Key, row.Err = decodeAccount(line)
+		return row, nil
+	}
+	if err := jr.s.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// decodeAccount decodes the account and key of a JSON Lines row
+func decodeAccount(line []byte) (*models.Account, string, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(line, &raw); err != nil {
+		return nil, "", fmt.Errorf("invalid json: %w", err)
+	}
+	var key string
+	if k, ok := raw[columnKey]; ok {
+		if err := json.Unmarshal(k, &key); err != nil {
+			return nil, "", fmt.Errorf("invalid key: %w", err)
+		}
+		delete(raw, columnKey)
+	}
+
+	account := &models.Account{}
+	if _, ok := raw["attributes"]; ok {
+		if err := decodeStrict(raw, account); err != nil {
+			return nil, key, err
+		}
+	} else {
+		// exported files have the version, which is set by the API
+		delete(raw, columnVersion)
+		for name, dst := range map[string]**strfmt.UUID{columnID: &account.ID, columnOrganisationID: &account.OrganisationID} {
+			if v, ok := raw[name]; ok {
+				if err := json.Unmarshal(v, dst); err != nil {
+					return nil, key, fmt.Errorf("invalid %s: %w", name, err)
+				}
+				delete(raw, name)
+			}
+		}
+		account.Attributes = &models.AccountAttributes{}
+		if err := decodeStrict(raw, account.Attributes); err != nil {
+			return nil, key, err
+		}
+	}
+	if account.Type == "" {
+		account.Type = "accounts"
+	}
+	return account, key, nil
+}
+
+// decodeStrict decodes the fields of raw into v, failing on the fields unknown to v
+func decodeStrict(raw map[string]json.RawMessage, v interface{}) error {
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid account: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package bulk_test
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/localhost418/accountclient/bulk"
+	"github.com/localhost418/accountclient/generated/models"
+)
+
+// readAll reads the rows of r
+func readAll(t *testing.T, r bulk.RowReader) []*bulk.Row {
+	var rows []*bulk.Row
+	for {
+		row, err := r.Next()
+		if err == io.EOF {
+			return rows
+		}
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		rows = append(rows, row)
+	}
+}
+
+func TestCSVReader(t *testing.T) {
+	accountID := strfmt.UUID("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+	country := "GB"
+	joint := true
+	src := "\ufeffid,key,country,bank_id,name,joint_account,private_identification.city\n" +
+		"ad27e265-9605-4b4b-a0e5-3003ea9cc4dc,,GB,400300,Jane Doe|J. Doe,true,London\n" +
+		",cust-2,GB,,,,\n" +
+		",cust-3,GB,,,maybe,\n" +
+		"too,few\n"
+
+	r, err := bulk.NewCSVReader(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	rows := readAll(t, r)
+	if len(rows) != 4 {
+		t.Fatalf("wrong number of rows: want 4 got %d", len(rows))
+	}
+
+	want := &models.Account{
+		ID:   &accountID,
+		Type: "accounts",
+		Attributes: &models.AccountAttributes{
+			Country:               &country,
+			BankID:                "400300",
+			Name:                  []string{"Jane Doe", "J. Doe"},
+			JointAccount:          &joint,
+			PrivateIdentification: &models.AccountAttributesPrivateIdentification{City: "London"},
+		},
+	}
+	if rows[0].Number != 1 || rows[0].Err != nil || !reflect.DeepEqual(rows[0].Account, want) {
+		t.Fatalf("wrong row: want %+v got %+v (error %v)", want.Attributes, rows[0].Account.Attributes, rows[0].Err)
+	}
+	if rows[1].Key != "cust-2" || rows[1].Account.ID != nil || rows[1].Account.Attributes.BankID != "" {
+		t.Fatalf("wrong row %+v", rows[1])
+	}
+	if rows[2].Err == nil || rows[2].Key != "cust-3" {
+		t.Fatalf("expected an invalid boolean error, got %+v", rows[2])
+	}
+	if rows[3].Number != 4 || rows[3].Err == nil {
+		t.Fatalf("expected a field count error, got %+v", rows[3])
+	}
+}
+
+func TestCSVReaderHeader(t *testing.T) {
+	tt := []struct {
+		name   string
+		header string
+		err    string
+	}{
+		{name: "unknown column", header: "country,bank\n", err: `unknown column "bank"`},
+		{name: "unknown nested column", header: "private_identification.nickname\n", err: `unknown column "private_identification.nickname"`},
+		{name: "object column", header: "organisation_identification.actors\n", err: `column "organisation_identification.actors" is not supported`},
+		{name: "duplicate column", header: "country, country\n", err: `duplicate column "country"`},
+		{name: "empty", header: "", err: "invalid csv header: EOF"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := bulk.NewCSVReader(strings.NewReader(tc.header))
+			if err == nil || err.Error() != tc.err {
+				t.Fatalf("unexpected error %v ; expected %v", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestJSONLReader(t *testing.T) {
+	accountID := strfmt.UUID("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+	organisationID := strfmt.UUID("eb0bd6f5-c3f5-44b2-b677-acd23cdde73c")
+	country := "GB"
+	src := `{"id":"ad27e265-9605-4b4b-a0e5-3003ea9cc4dc","organisation_id":"eb0bd6f5-c3f5-44b2-b677-acd23cdde73c","attributes":{"country":"GB"}}` + "\n" +
+		"\n" +
+		`{"key":"cust-2","country":"GB","bank_id":"400300"}` + "\n" +
+		`{"country":"GB","bank":"400300"}` + "\n" +
+		`{"country":` + "\n"
+
+	rows := readAll(t, bulk.NewJSONLReader(strings.NewReader(src)))
+	if len(rows) != 4 {
+		t.Fatalf("wrong number of rows: want 4 got %d", len(rows))
+	}
+
+	want := &models.Account{ID: &accountID, OrganisationID: &organisationID, Type: "accounts", Attributes: &models.AccountAttributes{Country: &country}}
+	if rows[0].Err != nil || !reflect.DeepEqual(rows[0].Account, want) {
+		t.Fatalf("wrong row: want %+v got %+v (error %v)", want, rows[0].Account, rows[0].Err)
+	}
+	if rows[1].Number != 3 || rows[1].Key != "cust-2" || rows[1].Account.Attributes.BankID != "400300" {
+		t.Fatalf("wrong row %+v", rows[1])
+	}
+	if rows[2].Err == nil || !strings.Contains(rows[2].Err.Error(), `unknown field "bank"`) {
+		t.Fatalf("expected an unknown field error, got %v", rows[2].Err)
+	}
+	if rows[3].Number != 5 || rows[3].Err == nil {
+		t.Fatalf("expected an invalid json error, got %+v", rows[3])
+	}
+}
+
+func TestFormatOf(t *testing.T) {
+	for name, want := range map[string]string{"accounts.CSV": bulk.FormatCSV, "a.jsonl": bulk.FormatJSONL, "a.ndjson": bulk.FormatJSONL, "a.json": ""} {
+		if got := bulk.FormatOf(name); got != want {
+			t.Fatalf("wrong format of %s: want %q got %q", name, want, got)
+		}
+	}
+	if _, err := bulk.NewReader("xml", strings.NewReader("")); err == nil {
+		t.Fatalf("expected an unknown format error")
+	}
+}
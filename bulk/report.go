@@ -0,0 +1,117 @@
+package bulk
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/localhost418/accountclient"
+)
+
+// Status is the outcome of the import of a row
+type Status string
+
+// Status values
+const (
+	StatusCreated   Status = "created"
+	StatusDuplicate Status = "duplicate"
+	StatusInvalid   Status = "invalid"
+	StatusFailed    Status = "failed"
+)
+
+// Done tells whether the row needs no other import attempt
+func (s Status) Done() bool {
+	return s == StatusCreated || s == StatusDuplicate
+}
+
+/*
+Result is the line of the report of a row.
+AccountID is the ID the account was (or would have been) created with, Error is set on the invalid and failed rows.
+A duplicate row has an account with its ID and attributes already, a row whose ID has another account failed.
+*/
+type Result struct {
+	Row       int          `json:"row"`
+	Key       string       `json:"key,omitempty"`
+	Status    Status       `json:"status"`
+	AccountID strfmt.UUID  `json:"account_id,omitempty"`
+	Error     *ResultError `json:"error,omitempty"`
+}
+
+// ResultError is the detail of the error of a row, the fields other than Message are those of the AccountError if any
+type ResultError struct {
+	Message         string            `json:"message"`
+	Kind            string            `json:"kind,omitempty"`
+	Method          string            `json:"method,omitempty"`
+	Endpoint        string            `json:"endpoint,omitempty"`
+	StatusCode      int               `json:"status_code,omitempty"`
+	APIErrorMessage string            `json:"api_error_message,omitempty"`
+	APIErrorCode    string            `json:"api_error_code,omitempty"`
+	Violations      []ResultViolation `json:"violations,omitempty"`
+}
+
+// ResultViolation is an invalid field of a row
+type ResultViolation struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// newResultError makes the ResultError of err
+func newResultError(err error) *ResultError {
+	res := &ResultError{Message: err.Error()}
+	var accErr *accountclient.AccountError
+	if !errors.As(err, &accErr) {
+		return res
+	}
+	if accErr.Kind != nil {
+		res.Kind = accErr.Kind.Error()
+	}
+	res.Method = accErr.Method
+	res.Endpoint = accErr.Endpoint
+	res.StatusCode = accErr.StatusCode
+	res.APIErrorMessage = accErr.APIErrorMessage
+	res.APIErrorCode = accErr.APIErrorCode
+	for _, v := range accErr.Violations {
+		res.Violations = append(res.Violations, ResultViolation{Field: v.Field, Message: v.Message})
+	}
+	return res
+}
+
+/*
+Completed reads a report and returns the rows which need no other import attempt (created or duplicate), to skip on resume.
+The last result of a row wins and an incomplete last line (an import stopped while writing it) is ignored.
+*/
+func Completed(report io.Reader) (map[int]bool, error) {
+	done := map[int]bool{}
+	s := bufio.NewScanner(report)
+	s.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	var bad error
+	line := 0
+	for s.Scan() {
+		line++
+		if len(s.Bytes()) == 0 {
+			continue
+		}
+		if bad != nil {
+			return nil, bad
+		}
+		res := &Result{}
+		if err := json.Unmarshal(s.Bytes(), res); err != nil {
+			// only an error when it is not the last line
+			bad = fmt.Errorf("invalid report line %d: %w", line, err)
+			continue
+		}
+		done[res.Row] = res.Status.Done()
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	for row, ok := range done {
+		if !ok {
+			delete(done, row)
+		}
+	}
+	return done, nil
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/localhost418/accountclient"
+	"github.com/localhost418/accountclient/generated/models"
+	"github.com/localhost418/accountclient/types"
+)
+
+func init() {
+	register(command{name: "create", usage: "create [-f file]", flags: createFlags})
+	register(command{name: "fetch", usage: "fetch -id id", flags: fetchFlags})
+	register(command{name: "delete", usage: "delete -id id -version version", flags: deleteFlags})
+	register(command{name: "list", usage: "list [-page n] [-size n] [-all] [-country GB,FR] [filters]", flags: listFlags})
+	register(command{name: "amend", usage: "amend -id id -version version [-f file]", flags: amendFlags})
+}
+
+func createFlags(fs *flag.FlagSet) func(e *env) error {
+	file := fs.String("f", "-", "JSON file of the account, - for stdin")
+	return func(e *env) error {
+		account := &models.Account{}
+		if err := readAccount(e, *file, account); err != nil {
+			return err
+		}
+		res, err := e.client.CreateAccount(&types.CreateAccountRequest{Data: account})
+		if err != nil {
+			return err
+		}
+		return printAccount(e, res.Data)
+	}
+}
+
+func fetchFlags(fs *flag.FlagSet) func(e *env) error {
+	id := fs.String("id", "", "account ID")
+	return func(e *env) error {
+		if *id == "" {
+			return errUsage
+		}
+		res, err := e.client.FetchAccount(&types.FetchAccountRequest{AccountID: strfmt.UUID(*id)})
+		if err != nil {
+			return err
+		}
+		return printAccount(e, res.Data)
+	}
+}
+
+func deleteFlags(fs *flag.FlagSet) func(e *env) error {
+	id := fs.String("id", "", "account ID")
+	version := fs.Int("version", -1, "current version of the account")
+	return func(e *env) error {
+		if *id == "" || *version < 0 {
+			return errUsage
+		}
+		_, err := e.client.DeleteAccount(&types.DeleteAccountRequest{AccountID: strfmt.UUID(*id), Version: *version})
+		return err
+	}
+}
+
+func listFlags(fs *flag.FlagSet) func(e *env) error {
+	page := fs.Int("page", 0, "page number")
+	size := fs.Int("size", 0, "page size (API default when 0)")
+	all := fs.Bool("all", false, "list every page")
+	filters := filterFlags(fs)
+	return func(e *env) error {
+		req := filters()
+		req.PageNumber = *page
+		req.PageSize = *size
+
+		if !*all {
+			res, err := e.client.ListAccounts(req)
+			if err != nil {
+				return err
+			}
+			return printAccounts(e, res.Data)
+		}
+		var accounts []*models.Account
+		it := e.client.ListAccountsIterator(context.Background(), req, &accountclient.IteratorOptions{Prefetch: true})
+		for it.Next() {
+			accounts = append(accounts, it.Account())
+		}
+		if err := it.Err(); err != nil {
+			return err
+		}
+		return printAccounts(e, accounts)
+	}
+}
+
+// filterFlags declares the list filter flags and returns the function making the list request of their values
+func filterFlags(fs *flag.FlagSet) func() *types.ListAccountsRequest {
+	organisationIDs := fs.String("filter-org", "", "comma separated organisation IDs")
+	bankIDCodes := fs.String("bank-id-code", "", "comma separated bank ID codes")
+	bankIDs := fs.String("bank-id", "", "comma separated bank IDs")
+	accountNumbers := fs.String("account-number", "", "comma separated account numbers")
+	countries := fs.String("country", "", "comma separated countries")
+	customerIDs := fs.String("customer-id", "", "comma separated customer IDs")
+	ibans := fs.String("iban", "", "comma separated IBANs")
+	return func() *types.ListAccountsRequest {
+		req := &types.ListAccountsRequest{
+			BankIDCodes:    splitList(*bankIDCodes),
+			BankIDs:        splitList(*bankIDs),
+			AccountNumbers: splitList(*accountNumbers),
+			Countries:      splitList(*countries),
+			CustomerIDs:    splitList(*customerIDs),
+			Ibans:          splitList(*ibans),
+		}
+		for _, id := range splitList(*organisationIDs) {
+			req.OrganisationIDs = append(req.OrganisationIDs, strfmt.UUID(id))
+		}
+		return req
+	}
+}
+
+func amendFlags(fs *flag.FlagSet) func(e *env) error {
+	id := fs.String("id", "", "account ID")
+	version := fs.Int("version", -1, "current version of the account")
+	file := fs.String("f", "-", "JSON file of the account (or of its attributes), - for stdin")
+	return func(e *env) error {
+		if *id == "" || *version < 0 {
+			return errUsage
+		}
+		account := &models.Account{}
+		if err := readAccount(e, *file, account); err != nil {
+			return err
+		}
+		res, err := e.client.AmendAccount(&types.AmendAccountRequest{
+			AccountID:  strfmt.UUID(*id),
+			Version:    *version,
+			Attributes: account.Attributes,
+		})
+		if err != nil {
+			return err
+		}
+		return printAccount(e, res.Data)
+	}
+}
+
+// readAccount decodes the account JSON of file (stdin for -) into account.
+// The JSON is the account, the request body wrapping it in "data", or the account attributes only.
+func readAccount(e *env, file string, account *models.Account) error {
+	var r io.Reader = e.stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return fmt.Errorf("invalid account JSON: %w", err)
+	}
+	if data, ok := raw["data"]; ok {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return fmt.Errorf("invalid account JSON: %w", err)
+		}
+	}
+	b, _ := json.Marshal(raw)
+	if _, ok := raw["attributes"]; !ok {
+		account.Attributes = &models.AccountAttributes{}
+		return json.Unmarshal(b, account.Attributes)
+	}
+	return json.Unmarshal(b, account)
+}
+
+// splitList splits a comma separated list, empty for an empty string
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/localhost418/accountclient/bulk"
+)
+
+func init() {
+	register(command{name: "export", usage: "export [-f file] [-format csv|jsonl] [-columns id,country] [-pseudonym-key file] [filters]", flags: exportFlags})
+}
+
+func exportFlags(fs *flag.FlagSet) func(e *env) error {
+	file := fs.String("f", "-", "CSV or JSON Lines file written, - for stdout")
+	format := fs.String("format", "", "format of the file: csv or jsonl (from the file extension when empty, csv for stdout)")
+	columns := fs.String("columns", "", "comma separated columns (every one when empty)")
+	keyFile := fs.String("pseudonym-key", "", "file of the key pseudonymising the PII columns")
+	pii := fs.String("pii", "", "comma separated PII columns (bulk.DefaultPIIColumns when empty)")
+	size := fs.Int("size", 0, "page size (API default when 0)")
+	filters := filterFlags(fs)
+	return func(e *env) error {
+		if *format == "" {
+			*format = bulk.FormatCSV
+			if *file != "-" {
+				*format = bulk.FormatOf(*file)
+			}
+		}
+		opts := &bulk.ExportOptions{Columns: splitList(*columns), PII: splitList(*pii), PageSize: *size}
+		if *keyFile != "" {
+			key, err := os.ReadFile(*keyFile)
+			if err != nil {
+				return err
+			}
+			if opts.PseudonymKey = bytes.TrimSpace(key); len(opts.PseudonymKey) == 0 {
+				return fmt.Errorf("empty pseudonym key in %s", *keyFile)
+			}
+		}
+		ex, err := bulk.NewExporter(e.client, opts)
+		if err != nil {
+			return err
+		}
+
+		if *file == "-" {
+			_, err := ex.Export(context.Background(), filters(), *format, e.stdout)
+			return err
+		}
+		f, err := os.Create(*file)
+		if err != nil {
+			return err
+		}
+		n, err := ex.Export(context.Background(), filters(), *format, f)
+		if err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		// stdout is left to the exported accounts
+		fmt.Fprintf(e.stderr, "exported %d accounts\n", n)
+		return nil
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/localhost418/accountclient/bulk"
+)
+
+func init() {
+	register(command{name: "import", usage: "import -f file [-format csv|jsonl] [-report file] [-resume] [-workers n]", flags: importFlags})
+}
+
+func importFlags(fs *flag.FlagSet) func(e *env) error {
+	file := fs.String("f", "", "CSV or JSON Lines file of the accounts, - for stdin")
+	format := fs.String("format", "", "format of the file: csv or jsonl (from the file extension when empty)")
+	reportFile := fs.String("report", "", "JSON Lines report of the rows (file.report.jsonl when empty)")
+	resume := fs.Bool("resume", false, "skip the rows created or duplicate in the report and append to it")
+	workers := fs.Int("workers", 4, "number of accounts created concurrently")
+	return func(e *env) error {
+		if *file == "" || (*file == "-" && (*format == "" || *reportFile == "")) {
+			return errUsage
+		}
+		if *format == "" {
+			*format = bulk.FormatOf(*file)
+		}
+		if *reportFile == "" {
+			*reportFile = *file + ".report.jsonl"
+		}
+
+		var r io.Reader = e.stdin
+		if *file != "-" {
+			f, err := os.Open(*file)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			r = f
+		}
+		src, err := bulk.NewReader(*format, r)
+		if err != nil {
+			return err
+		}
+		report, done, err := openReport(*reportFile, *resume)
+		if err != nil {
+			return err
+		}
+		defer report.Close()
+
+		im := bulk.NewImporter(e.client, &bulk.Options{Workers: *workers, Skip: done})
+		summary, err := im.Import(context.Background(), src, report)
+		if summary != nil {
+			fmt.Fprintf(e.stdout, "created %d, duplicates %d, invalid %d, failed %d, skipped %d\n",
+				summary.Created, summary.Duplicates, summary.Invalid, summary.Failed, summary.Skipped)
+		}
+		if err != nil {
+			return err
+		}
+		if err := report.Close(); err != nil {
+			return err
+		}
+		if n := summary.Invalid + summary.Failed; n > 0 {
+			return fmt.Errorf("%d rows not imported, see %s", n, *reportFile)
+		}
+		return nil
+	}
+}
+
+// openReport creates the report file, or opens it for append with the rows already imported when resuming.
+// An incomplete last line (an import stopped while writing it) is dropped so the appended results start on their own line.
+func openReport(name string, resume bool) (*os.File, map[int]bool, error) {
+	if !resume {
+		f, err := os.Create(name)
+		return f, nil, err
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, err
+	}
+	done, err := bulk.Completed(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := f.Truncate(int64(bytes.LastIndexByte(data, '\n') + 1)); err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	if _, err := f.Seek(0, io.SeekEnd); err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	return f, done, nil
+}
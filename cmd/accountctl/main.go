@@ -0,0 +1,211 @@
+/*
+Command accountctl creates, fetches, deletes, lists, amends, imports and exports accounts through the account API.
+
+	accountctl <command> [flags]
+
+The base URL, organisation ID and credentials are read from the flags, or from the ACCOUNT_API_URL,
+ACCOUNT_API_ORGANISATION_ID, ACCOUNT_API_CLIENT_ID and ACCOUNT_API_CLIENT_SECRET environment variables.
+Accounts are read as JSON from a file or stdin and printed as JSON, a table or YAML, import and export use CSV or JSON Lines files (see package bulk).
+The exit code tells the kind of failure (see exitCode).
+*/
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/localhost418/accountclient"
+	"github.com/localhost418/accountclient/signing"
+)
+
+// environment variables of the global flags
+const (
+	envURL            = "ACCOUNT_API_URL"
+	envOrganisationID = "ACCOUNT_API_ORGANISATION_ID"
+	envClientID       = "ACCOUNT_API_CLIENT_ID"
+	envClientSecret   = "ACCOUNT_API_CLIENT_SECRET"
+)
+
+// exit codes
+const (
+	exitOK              = 0
+	exitFailure         = 1
+	exitUsage           = 2
+	exitInvalidRequest  = 3
+	exitRequestFailed   = 4
+	exitAuthentication  = 5
+	exitCancelled       = 6
+	exitAPIFailure      = 7
+	exitNotFound        = 8
+	exitConflict        = 9
+	exitInvalidResponse = 10
+)
+
+// errUsage is returned on invalid command line, after the usage is printed
+var errUsage = errors.New("invalid usage")
+
+// command is an accountctl subcommand, run with its flags parsed
+type command struct {
+	name  string
+	usage string
+	flags func(fs *flag.FlagSet) func(e *env) error
+}
+
+// env is what a command runs with
+type env struct {
+	client *accountclient.Client
+	args   []string
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	output string
+}
+
+var commands = map[string]command{}
+
+func register(c command) {
+	commands[c.name] = c
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr, os.Getenv))
+}
+
+// run runs the command of args and returns the exit code
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer, getenv func(string) string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "help" {
+		printUsage(stderr)
+		return exitUsage
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n", args[0])
+		printUsage(stderr)
+		return exitUsage
+	}
+
+	fs := flag.NewFlagSet("accountctl "+cmd.name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	baseURL := fs.String("url", getenv(envURL), "account API base URL (env "+envURL+")")
+	organisationID := fs.String("org", getenv(envOrganisationID), "default organisation ID (env "+envOrganisationID+")")
+	clientID := fs.String("client-id", getenv(envClientID), "OAuth2 client ID (env "+envClientID+")")
+	clientSecret := fs.String("client-secret", getenv(envClientSecret), "OAuth2 client secret (env "+envClientSecret+")")
+	keyID := fs.String("signing-key-id", "", "ID of the key signing the requests")
+	keyFile := fs.String("signing-key", "", "PEM file of the private key signing the requests")
+	timeout := fs.Duration("timeout", 30*time.Second, "timeout of each request")
+	output := fs.String("o", "json", "output format: json, table or yaml")
+	runCmd := cmd.flags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: accountctl %s\n", cmd.usage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if *output != "json" && *output != "table" && *output != "yaml" {
+		fmt.Fprintf(stderr, "unknown output format %q\n", *output)
+		return exitUsage
+	}
+	if *baseURL == "" {
+		fmt.Fprintf(stderr, "no base URL, set -url or %s\n", envURL)
+		return exitUsage
+	}
+
+	opts := []accountclient.Option{accountclient.WithTimeout(*timeout), accountclient.WithUserAgent("accountctl")}
+	if *organisationID != "" {
+		opts = append(opts, accountclient.WithOrganisationID(*organisationID))
+	}
+	if *clientID != "" {
+		opts = append(opts, accountclient.WithClientCredentials(*clientID, *clientSecret))
+	}
+	if *keyFile != "" {
+		signer, err := loadSigner(*keyID, *keyFile)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+		opts = append(opts, accountclient.WithSigner(signer))
+	}
+	client, err := accountclient.NewClientWithOptions(*baseURL, opts...)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	err = runCmd(&env{client: client, args: fs.Args(), stdin: stdin, stdout: stdout, stderr: stderr, output: *output})
+	if errors.Is(err, errUsage) {
+		fs.Usage()
+		return exitUsage
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+	}
+	return exitCode(err)
+}
+
+// exitCode maps the kind of err to an exit code
+func exitCode(err error) int {
+	var accErr *accountclient.AccountError
+	switch {
+	case err == nil:
+		return exitOK
+	case !errors.As(err, &accErr):
+		return exitFailure
+	case errors.Is(err, accountclient.ErrNoRequest), errors.Is(err, accountclient.ErrInvalidBody),
+		errors.Is(err, accountclient.ErrInvalidRequest), errors.Is(err, accountclient.ErrValidation),
+		errors.Is(err, accountclient.ErrInvalidTransition):
+		return exitInvalidRequest
+	case errors.Is(err, accountclient.ErrDoRequest):
+		return exitRequestFailed
+	case errors.Is(err, accountclient.ErrAuthentication):
+		return exitAuthentication
+	case errors.Is(err, accountclient.ErrCancelled):
+		return exitCancelled
+	case errors.Is(err, accountclient.ErrVersionConflict), errors.Is(err, accountclient.ErrConflictingDuplicate):
+		return exitConflict
+	case errors.Is(err, accountclient.ErrAPIFailure) && accErr.StatusCode == 404:
+		return exitNotFound
+	case errors.Is(err, accountclient.ErrAPIFailure) && accErr.StatusCode == 409:
+		return exitConflict
+	case errors.Is(err, accountclient.ErrAPIFailure):
+		return exitAPIFailure
+	case errors.Is(err, accountclient.ErrInvalidResponse):
+		return exitInvalidResponse
+	}
+	return exitFailure
+}
+
+// loadSigner makes the request signer of the PEM private key in file
+func loadSigner(keyID, file string) (*signing.Signer, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	key, err := signing.ParsePrivateKey(data)
+	if err != nil {
+		return nil, err
+	}
+	return signing.NewSigner(keyID, key)
+}
+
+func printUsage(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	lines := make([]string, 0, len(names))
+	for _, name := range names {
+		lines = append(lines, "  accountctl "+commands[name].usage)
+	}
+	fmt.Fprintf(w, "usage:\n%s\n\nrun accountctl <command> -h for the flags of a command\n", strings.Join(lines, "\n"))
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/localhost418/accountclient/accountapitest"
+	"github.com/localhost418/accountclient/generated/models"
+)
+
+const account = `{"data":{"id":"ad27e265-9605-4b4b-a0e5-3003ea9cc4dc","organisation_id":"eb0bd6f5-c3f5-44b2-b677-acd23cdde73c",` +
+	`"type":"accounts","attributes":{"country":"GB","bank_id":"400300","bic":"NWBKGB22","name":["Jane Doe"]}}}`
+
+// runCmd runs accountctl with args against url and returns its exit code and outputs
+func runCmd(url, stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	getenv := func(key string) string {
+		if key == envURL {
+			return url
+		}
+		return ""
+	}
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr, getenv)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestAccountctl(t *testing.T) {
+	srv := accountapitest.NewServer()
+	defer srv.Close()
+
+	code, out, errOut := runCmd(srv.URL, account, "create")
+	if code != exitOK {
+		t.Fatalf("create failed with code %d: %s", code, errOut)
+	}
+	created := &models.Account{}
+	if err := json.Unmarshal([]byte(out), created); err != nil || created.ID.String() != "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc" {
+		t.Fatalf("wrong created account %s (error %v)", out, err)
+	}
+
+	tt := []struct {
+		name  string
+		stdin string
+		args  []string
+		code  int
+		out   string
+	}{
+		{name: "duplicate", stdin: account, args: []string{"create"}, code: exitConflict},
+		{name: "invalid json", stdin: "{", args: []string{"create"}, code: exitFailure},
+		{name: "fetch yaml", args: []string{"fetch", "-o", "yaml", "-id", "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc"}, out: "  bank_id: \"400300\"\n"},
+		{name: "fetch unknown", args: []string{"fetch", "-id", "00000000-0000-0000-0000-000000000000"}, code: exitNotFound},
+		{name: "list table", args: []string{"list", "-o", "table", "-all", "-country", "GB"}, out: "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc  0        GB       400300"},
+		{name: "list one", args: []string{"list", "-country", "GB"}, out: "[\n  {\n"},
+		{name: "list none", args: []string{"list", "-all", "-country", "FR"}, out: "[]\n"},
+		{name: "amend attributes", stdin: `{"country":"GB","name":["John Doe"]}`, args: []string{"amend", "-id", "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "-version", "0"}, out: `"John Doe"`},
+		{name: "amend stale version", stdin: `{"country":"GB"}`, args: []string{"amend", "-id", "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "-version", "0"}, code: exitConflict},
+		{name: "delete", args: []string{"delete", "-id", "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "-version", "1"}},
+		{name: "delete without version", args: []string{"delete", "-id", "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc"}, code: exitUsage},
+		{name: "unknown command", args: []string{"undelete"}, code: exitUsage},
+		{name: "unknown output", args: []string{"list", "-o", "xml"}, code: exitUsage},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			code, out, errOut := runCmd(srv.URL, tc.stdin, tc.args...)
+			if code != tc.code {
+				t.Fatalf("wrong exit code: want %d got %d (%s)", tc.code, code, errOut)
+			}
+			if !strings.Contains(out, tc.out) {
+				t.Fatalf("output does not contain %q:\n%s", tc.out, out)
+			}
+		})
+	}
+}
+
+func TestAccountctlNoURL(t *testing.T) {
+	if code, _, _ := runCmd("", "", "list"); code != exitUsage {
+		t.Fatalf("wrong exit code %d", code)
+	}
+	if code, _, _ := runCmd("http://127.0.0.1:1", "", "list", "-timeout", "1s"); code != exitRequestFailed {
+		t.Fatalf("wrong exit code %d", code)
+	}
+}
+
+func TestAccountctlImport(t *testing.T) {
+	srv := accountapitest.NewServer()
+	defer srv.Close()
+
+	file := filepath.Join(t.TempDir(), "accounts.csv")
+	csv := "key,country,bank_id,bank_id_code,bic\n" +
+		"cust-1,GB,400300,GBDSC,NWBKGB22\n" +
+		"cust-2,GB,400301,GBDSC,NWBK\n"
+	if err := os.WriteFile(file, []byte(csv), 0o600); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	code, out, errOut := runCmd(srv.URL, "", "import", "-org", "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c", "-f", file)
+	if code != exitFailure || out != "created 1, duplicates 0, invalid 1, failed 0, skipped 0\n" || !strings.Contains(errOut, "1 rows not imported") {
+		t.Fatalf("wrong import: code %d, output %q, error %q", code, out, errOut)
+	}
+	report, err := os.ReadFile(file + ".report.jsonl")
+	if err != nil || strings.Count(string(report), "\n") != 2 {
+		t.Fatalf("wrong report %q (error %v)", report, err)
+	}
+
+	// the resumed import skips the created row and reports the invalid one again
+	code, out, _ = runCmd(srv.URL, "", "import", "-org", "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c", "-f", file, "-resume")
+	if code != exitFailure || out != "created 0, duplicates 0, invalid 1, failed 0, skipped 1\n" {
+		t.Fatalf("wrong resumed import: code %d, output %q", code, out)
+	}
+	report, _ = os.ReadFile(file + ".report.jsonl")
+	if strings.Count(string(report), "\n") != 3 {
+		t.Fatalf("wrong report %q", report)
+	}
+
+	if code, _, _ := runCmd(srv.URL, "", "import", "-f", "-"); code != exitUsage {
+		t.Fatalf("wrong exit code %d", code)
+	}
+}
+
+func TestAccountctlExport(t *testing.T) {
+	srv := accountapitest.NewServer()
+	defer srv.Close()
+	if code, _, errOut := runCmd(srv.URL, account, "create"); code != exitOK {
+		t.Fatalf("create failed with code %d: %s", code, errOut)
+	}
+
+	code, out, errOut := runCmd(srv.URL, "", "export", "-columns", "id,country,name", "-country", "GB")
+	want := "id,country,name\nad27e265-9605-4b4b-a0e5-3003ea9cc4dc,GB,Jane Doe\n"
+	if code != exitOK || out != want {
+		t.Fatalf("wrong export: code %d, output %q (%s)", code, out, errOut)
+	}
+
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "key")
+	if err := os.WriteFile(keyFile, []byte("secret\n"), 0o600); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	file := filepath.Join(dir, "accounts.jsonl")
+	code, out, errOut = runCmd(srv.URL, "", "export", "-f", file, "-columns", "bank_id,name", "-pseudonym-key", keyFile)
+	if code != exitOK || out != "" || errOut != "exported 1 accounts\n" {
+		t.Fatalf("wrong export: code %d, output %q (%s)", code, out, errOut)
+	}
+	exported, _ := os.ReadFile(file)
+	if !strings.HasPrefix(string(exported), `{"bank_id":"400300","name":["`) || strings.Contains(string(exported), "Jane") {
+		t.Fatalf("wrong pseudonymised export %s", exported)
+	}
+
+	if code, _, _ := runCmd(srv.URL, "", "export", "-columns", "unknown"); code != exitFailure {
+		t.Fatalf("wrong exit code %d", code)
+	}
+}
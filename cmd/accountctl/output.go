@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/localhost418/accountclient/generated/models"
+	"gopkg.in/yaml.v2"
+)
+
+// printAccount prints the account of a get, create or amend in the output format, as an object
+func printAccount(e *env, account *models.Account) error {
+	return printValue(e, account, []*models.Account{account})
+}
+
+// printAccounts prints the accounts of a list in the output format, always as a list (empty when there is none)
+func printAccounts(e *env, accounts []*models.Account) error {
+	if accounts == nil {
+		accounts = []*models.Account{}
+	}
+	return printValue(e, accounts, accounts)
+}
+
+// printValue prints v in the output format, the table having a line per account
+func printValue(e *env, v interface{}, accounts []*models.Account) error {
+	switch e.output {
+	case "table":
+		return printTable(e, accounts)
+	case "yaml":
+		// through JSON so the YAML keys are the JSON names of the fields
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		var generic interface{}
+		if err := json.Unmarshal(b, &generic); err != nil {
+			return err
+		}
+		out, err := yaml.Marshal(generic)
+		if err != nil {
+			return err
+		}
+		_, err = e.stdout.Write(out)
+		return err
+	}
+	enc := json.NewEncoder(e.stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// printTable prints one line per account with its main attributes
+func printTable(e *env, accounts []*models.Account) error {
+	w := tabwriter.NewWriter(e.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tVERSION\tCOUNTRY\tBANK ID\tBIC\tACCOUNT NUMBER\tIBAN\tNAME\tSTATUS")
+	for _, a := range accounts {
+		var id, version string
+		if a.ID != nil {
+			id = a.ID.String()
+		}
+		if a.Version != nil {
+			version = fmt.Sprint(*a.Version)
+		}
+		attrs := a.Attributes
+		if attrs == nil {
+			attrs = &models.AccountAttributes{}
+		}
+		var country string
+		if attrs.Country != nil {
+			country = *attrs.Country
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", id, version, country, attrs.BankID, attrs.Bic,
+			attrs.AccountNumber, attrs.Iban, strings.Join(attrs.Name, " "), attrs.Status)
+	}
+	return w.Flush()
+}
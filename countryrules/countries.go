@@ -0,0 +1,129 @@
+package countryrules
+
+import (
+	"regexp"
+	"strings"
+)
+
+// bic is the rule of a bic which is required
+var bic = Field{Presence: Required, Pattern: regexp.MustCompile(`^([A-Z]{6}[A-Z0-9]{2}|[A-Z]{6}[A-Z0-9]{5})$`), Format: "8 or 11 characters"}
+
+// fixed is a field rule matching exactly value
+func fixed(presence Presence, value string) Field {
+	return Field{Presence: presence, Pattern: regexp.MustCompile(`^` + regexp.QuoteMeta(value) + `$`), Format: value}
+}
+
+// digits is a field rule of n digits (n is a count or a "min,max" range)
+func digits(presence Presence, n string) Field {
+	return Field{Presence: presence, Pattern: regexp.MustCompile(`^[0-9]{` + n + `}$`), Format: strings.Replace(n, ",", " to ", 1) + " digits"}
+}
+
+// chars is a field rule of n alphanumeric characters (n is a count or a "min,max" range)
+func chars(presence Presence, n string) Field {
+	return Field{Presence: presence, Pattern: regexp.MustCompile(`^[A-Z0-9]{` + n + `}$`), Format: strings.Replace(n, ",", " to ", 1) + " characters"}
+}
+
+var forbidden = Field{Presence: Forbidden}
+
+// rules of the countries supported by the account API, see https://api-docs.form3.tech/api.html#organisation-accounts
+var rules = map[string]CountryRule{
+	"AU": {
+		BankID:        digits(Optional, "6"),
+		BankIDCode:    fixed(Required, "AUBSB"),
+		Bic:           bic,
+		AccountNumber: Field{Pattern: regexp.MustCompile(`^[1-9][0-9]{5,9}$`), Format: "6 to 10 digits not starting with 0"},
+		Iban:          forbidden,
+	},
+	"BE": {
+		BankID:        digits(Required, "3"),
+		BankIDCode:    fixed(Required, "BE"),
+		AccountNumber: digits(Optional, "7"),
+	},
+	"CA": {
+		BankID:        Field{Pattern: regexp.MustCompile(`^0[0-9]{8}$`), Format: "9 digits starting with 0"},
+		BankIDCode:    fixed(Required, "CACPA"),
+		Bic:           bic,
+		AccountNumber: digits(Optional, "7,12"),
+		Iban:          forbidden,
+	},
+	"CH": {
+		BankID:        digits(Required, "5"),
+		BankIDCode:    fixed(Required, "CHBCC"),
+		AccountNumber: chars(Optional, "12"),
+	},
+	"DE": {
+		BankID:        digits(Required, "8"),
+		BankIDCode:    fixed(Required, "DEBLZ"),
+		AccountNumber: digits(Optional, "7"),
+	},
+	"ES": {
+		BankID:        digits(Required, "8"),
+		BankIDCode:    fixed(Required, "ESNCC"),
+		AccountNumber: digits(Optional, "10"),
+	},
+	"FR": {
+		BankID:        chars(Required, "10"),
+		BankIDCode:    fixed(Required, "FR"),
+		AccountNumber: chars(Optional, "10"),
+	},
+	"GB": {
+		BankID:        digits(Required, "6"),
+		BankIDCode:    fixed(Required, "GBDSC"),
+		Bic:           bic,
+		AccountNumber: digits(Optional, "8"),
+	},
+	"GR": {
+		BankID:        digits(Required, "7"),
+		BankIDCode:    fixed(Required, "GRBIC"),
+		AccountNumber: digits(Optional, "16"),
+	},
+	"HK": {
+		BankID:        digits(Optional, "3"),
+		BankIDCode:    fixed(Required, "HKNCC"),
+		Bic:           bic,
+		AccountNumber: digits(Optional, "9,12"),
+		Iban:          forbidden,
+	},
+	"IT": {
+		BankID:        chars(Required, "10,11"),
+		BankIDCode:    fixed(Required, "ITNCC"),
+		AccountNumber: chars(Optional, "12"),
+	},
+	"LU": {
+		BankID:        digits(Required, "3"),
+		BankIDCode:    fixed(Required, "LULUX"),
+		AccountNumber: chars(Optional, "13"),
+	},
+	"NL": {
+		BankID:        forbidden,
+		BankIDCode:    forbidden,
+		Bic:           bic,
+		AccountNumber: digits(Optional, "10"),
+	},
+	"PL": {
+		BankID:        digits(Required, "8"),
+		BankIDCode:    fixed(Required, "PLKNR"),
+		AccountNumber: digits(Optional, "16"),
+	},
+	"PT": {
+		BankID:        digits(Required, "8"),
+		BankIDCode:    fixed(Required, "PTNCC"),
+		AccountNumber: digits(Optional, "11"),
+	},
+	"US": {
+		BankID:        digits(Required, "9"),
+		BankIDCode:    fixed(Required, "USABA"),
+		Bic:           bic,
+		AccountNumber: digits(Optional, "6,17"),
+		Iban:          forbidden,
+	},
+}
+
+// Default returns a new Registry with the rules of the countries supported by the account API
+func Default() *Registry {
+	r := NewRegistry()
+	for country, rule := range rules {
+		r.Register(country, rule)
+	}
+	return r
+}
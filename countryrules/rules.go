@@ -0,0 +1,138 @@
+// Package countryrules validates the country specific requirements on the bank_id, bank_id_code, bic, account_number and iban of accounts
+package countryrules
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/localhost418/accountclient/generated/models"
+)
+
+// FieldError is a failed country rule on an account attribute
+type FieldError struct {
+	// Field is the JSON name of the attribute, e.g. "bank_id"
+	Field   string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// Rule validates the attributes of the accounts of a country
+type Rule interface {
+	Validate(attrs *models.AccountAttributes) []FieldError
+}
+
+// RuleFunc adapts a function to a Rule
+type RuleFunc func(attrs *models.AccountAttributes) []FieldError
+
+// Validate calls f(attrs)
+func (f RuleFunc) Validate(attrs *models.AccountAttributes) []FieldError {
+	return f(attrs)
+}
+
+// Presence tells whether an attribute must, may or must not be set
+type Presence int
+
+const (
+	// Optional attribute, checked against the pattern when set
+	Optional Presence = iota
+	// Required attribute
+	Required
+	// Forbidden attribute (not supported in the country)
+	Forbidden
+)
+
+// Field is the rule of an attribute, Format describes Pattern in error messages (e.g. "6 digits")
+type Field struct {
+	Presence Presence
+	Pattern  *regexp.Regexp
+	Format   string
+}
+
+func (f Field) check(name, value string) []FieldError {
+	switch {
+	case value == "" && f.Presence == Required:
+		return []FieldError{{Field: name, Message: "is required"}}
+	case value == "":
+		return nil
+	case f.Presence == Forbidden:
+		return []FieldError{{Field: name, Message: "is not supported"}}
+	case f.Pattern != nil && !f.Pattern.MatchString(value):
+		return []FieldError{{Field: name, Message: "must be " + f.Format}}
+	}
+	return nil
+}
+
+// CountryRule is the Rule of the attributes of a country
+type CountryRule struct {
+	BankID        Field
+	BankIDCode    Field
+	Bic           Field
+	AccountNumber Field
+	Iban          Field
+}
+
+// Validate checks each attribute against its field rule
+func (r CountryRule) Validate(attrs *models.AccountAttributes) []FieldError {
+	var errs []FieldError
+	errs = append(errs, r.BankID.check("bank_id", attrs.BankID)...)
+	errs = append(errs, r.BankIDCode.check("bank_id_code", attrs.BankIDCode)...)
+	errs = append(errs, r.Bic.check("bic", attrs.Bic)...)
+	errs = append(errs, r.AccountNumber.check("account_number", attrs.AccountNumber)...)
+	errs = append(errs, r.Iban.check("iban", attrs.Iban)...)
+	return errs
+}
+
+// Registry holds the rules by country code, it is safe for concurrent use
+type Registry struct {
+	mu    sync.RWMutex
+	rules map[string]Rule
+}
+
+// NewRegistry creates an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{rules: map[string]Rule{}}
+}
+
+// Register sets the rule of country (ISO 3166-1 alpha-2 code), replacing any previous one
+func (r *Registry) Register(country string, rule Rule) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rules[strings.ToUpper(country)] = rule
+}
+
+// Lookup returns the rule of country if any
+func (r *Registry) Lookup(country string) (Rule, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rule, ok := r.rules[strings.ToUpper(country)]
+	return rule, ok
+}
+
+// Countries returns the sorted codes of the registered countries
+func (r *Registry) Countries() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	countries := make([]string, 0, len(r.rules))
+	for country := range r.rules {
+		countries = append(countries, country)
+	}
+	sort.Strings(countries)
+	return countries
+}
+
+// Validate checks attrs against the rule of their country, countries without a rule are not checked
+func (r *Registry) Validate(attrs *models.AccountAttributes) []FieldError {
+	if attrs == nil || attrs.Country == nil {
+		return nil
+	}
+	rule, ok := r.Lookup(*attrs.Country)
+	if !ok {
+		return nil
+	}
+	return rule.Validate(attrs)
+}
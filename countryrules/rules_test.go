@@ -0,0 +1,106 @@
+package countryrules_test
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/localhost418/accountclient/countryrules"
+	"github.com/localhost418/accountclient/generated/models"
+)
+
+// attributes makes account attributes of country
+func attributes(country, bankID, bankIDCode, bic, accountNumber, iban string) *models.AccountAttributes {
+	return &models.AccountAttributes{
+		Country:       &country,
+		BankID:        bankID,
+		BankIDCode:    bankIDCode,
+		Bic:           bic,
+		AccountNumber: accountNumber,
+		Iban:          iban,
+	}
+}
+
+func TestDefaultRules(t *testing.T) {
+	tt := []struct {
+		name   string
+		attrs  *models.AccountAttributes
+		fields []string
+	}{
+		{name: "AU valid", attrs: attributes("AU", "123456", "AUBSB", "NWBKAU21", "1234567", "")},
+		{name: "AU iban", attrs: attributes("AU", "", "AUBSB", "NWBKAU21", "0123456", "AU00000"), fields: []string{"account_number", "iban"}},
+		{name: "BE valid", attrs: attributes("BE", "123", "BE", "", "1234567", "")},
+		{name: "BE bank id", attrs: attributes("BE", "1234", "BE", "", "", ""), fields: []string{"bank_id"}},
+		{name: "CA valid", attrs: attributes("CA", "012345678", "CACPA", "NWBKCA21", "1234567", "")},
+		{name: "CA bank id", attrs: attributes("CA", "123456789", "CACPA", "", "", ""), fields: []string{"bank_id", "bic"}},
+		{name: "CH valid", attrs: attributes("CH", "12345", "CHBCC", "", "12345678901A", "")},
+		{name: "CH bank id code", attrs: attributes("CH", "12345", "CH", "", "", ""), fields: []string{"bank_id_code"}},
+		{name: "DE valid", attrs: attributes("DE", "12345678", "DEBLZ", "", "1234567", "")},
+		{name: "DE missing", attrs: attributes("DE", "", "", "", "", ""), fields: []string{"bank_id", "bank_id_code"}},
+		{name: "ES valid", attrs: attributes("ES", "12345678", "ESNCC", "", "1234567890", "")},
+		{name: "ES account number", attrs: attributes("ES", "12345678", "ESNCC", "", "123", ""), fields: []string{"account_number"}},
+		{name: "FR valid", attrs: attributes("FR", "12345678AB", "FR", "", "1234567890", "")},
+		{name: "FR bank id", attrs: attributes("FR", "12345", "FR", "", "", ""), fields: []string{"bank_id"}},
+		{name: "GB valid", attrs: attributes("GB", "400300", "GBDSC", "NWBKGB22", "41426819", "GB11NWBK40030041426819")},
+		{name: "GB invalid", attrs: attributes("GB", "40030", "GBSDC", "NWBK", "4142681", ""), fields: []string{"account_number", "bank_id", "bank_id_code", "bic"}},
+		{name: "GR valid", attrs: attributes("GR", "1234567", "GRBIC", "", "1234567890123456", "")},
+		{name: "GR bank id code", attrs: attributes("GR", "1234567", "", "", "", ""), fields: []string{"bank_id_code"}},
+		{name: "HK valid", attrs: attributes("HK", "123", "HKNCC", "NWBKHK21", "123456789", "")},
+		{name: "HK iban", attrs: attributes("HK", "", "HKNCC", "NWBKHK21", "", "HK00123"), fields: []string{"iban"}},
+		{name: "IT valid", attrs: attributes("IT", "X0542811101", "ITNCC", "", "000000123456", "")},
+		{name: "IT bank id", attrs: attributes("IT", "X05428", "ITNCC", "", "", ""), fields: []string{"bank_id"}},
+		{name: "LU valid", attrs: attributes("LU", "123", "LULUX", "", "1234567890123", "")},
+		{name: "LU account number", attrs: attributes("LU", "123", "LULUX", "", "12345", ""), fields: []string{"account_number"}},
+		{name: "NL valid", attrs: attributes("NL", "", "", "ABNANL2A", "0417164300", "")},
+		{name: "NL bank id", attrs: attributes("NL", "1234", "NLBNK", "", "", ""), fields: []string{"bank_id", "bank_id_code", "bic"}},
+		{name: "PL valid", attrs: attributes("PL", "12345678", "PLKNR", "", "1234567890123456", "")},
+		{name: "PL bank id", attrs: attributes("PL", "123", "PLKNR", "", "", ""), fields: []string{"bank_id"}},
+		{name: "PT valid", attrs: attributes("PT", "12345678", "PTNCC", "", "12345678901", "")},
+		{name: "PT missing", attrs: attributes("PT", "", "PTNCC", "", "", ""), fields: []string{"bank_id"}},
+		{name: "US valid", attrs: attributes("US", "021000021", "USABA", "CHASUS33", "123456789", "")},
+		{name: "US iban", attrs: attributes("US", "021000021", "USABA", "CHASUS33", "12345", "US00"), fields: []string{"account_number", "iban"}},
+		{name: "unknown country", attrs: attributes("ZZ", "", "", "", "", "")},
+		{name: "lower case country", attrs: attributes("gb", "", "", "", "", ""), fields: []string{"bank_id", "bank_id_code", "bic"}},
+		{name: "no country", attrs: &models.AccountAttributes{}},
+		{name: "nil attributes"},
+	}
+
+	registry := countryrules.Default()
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var fields []string
+			for _, fe := range registry.Validate(tc.attrs) {
+				fields = append(fields, fe.Field)
+			}
+			sort.Strings(fields)
+			if !reflect.DeepEqual(fields, tc.fields) {
+				t.Fatalf("wrong fields: want %v got %v", tc.fields, fields)
+			}
+		})
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	registry := countryrules.NewRegistry()
+	if countries := registry.Countries(); len(countries) != 0 {
+		t.Fatalf("unexpected countries %v", countries)
+	}
+
+	registry.Register("xx", countryrules.RuleFunc(func(attrs *models.AccountAttributes) []countryrules.FieldError {
+		if attrs.Bic == "" {
+			return []countryrules.FieldError{{Field: "bic", Message: "is required"}}
+		}
+		return nil
+	}))
+	if _, ok := registry.Lookup("XX"); !ok {
+		t.Fatalf("rule not registered")
+	}
+	if countries := registry.Countries(); !reflect.DeepEqual(countries, []string{"XX"}) {
+		t.Fatalf("unexpected countries %v", countries)
+	}
+
+	errs := registry.Validate(attributes("XX", "", "", "", "", ""))
+	if len(errs) != 1 || errs[0].Error() != "bic: is required" {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+}
@@ -1,41 +1,136 @@
 package accountclient
 
-// AccountError for account errors
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/localhost418/accountclient/types"
+)
+
+// maxErrorBodySize caps the number of bytes of an error response body kept in AccountError
+const maxErrorBodySize = 1024
+
+/*
+AccountError for account errors.
+Kind is one of the Err* sentinel errors so callers can use errors.Is(err, ErrAPIFailure),
+Err is the underlying error if any (returned by Unwrap) and StatusCode is 0 when no response was received.
+APIErrorMessage and APIErrorCode are decoded from the error response body, Body holds the start of that body when it cannot be decoded.
+Violations lists the invalid fields of an ErrValidation error, sorted by field.
+*/
 type AccountError struct {
-	Message    string
-	StatusCode *int
-	Error      *error
+	Kind            error
+	Method          string
+	Endpoint        string
+	StatusCode      int
+	Err             error
+	APIErrorMessage string
+	APIErrorCode    string
+	Body            string
+	Violations      []Violation
 }
 
-// NewAccountError make a new AccountError from message and status
-func NewAccountError(msg string, status int, err *error) *AccountError {
-	var s *int
-	if status != -1 {
-		s = &status
-	}
+// NewAccountError make a new AccountError of kind for the method and endpoint, status and err are optional (0 and nil)
+func NewAccountError(kind error, method, endpoint string, status int, err error) *AccountError {
 	return &AccountError{
-		Message:    msg,
-		StatusCode: s,
-		Error:      err,
+		Kind:       kind,
+		Method:     method,
+		Endpoint:   endpoint,
+		StatusCode: status,
+		Err:        err,
+	}
+}
+
+// Error implements error
+func (e *AccountError) Error() string {
+	msg := fmt.Sprintf("'%s %s': %v", e.Method, e.Endpoint, e.Kind)
+	if e.StatusCode != 0 {
+		msg += fmt.Sprintf(" (status %d)", e.StatusCode)
+	}
+	switch {
+	case e.APIErrorMessage != "" && e.APIErrorCode != "":
+		msg += fmt.Sprintf(": %s [%s]", e.APIErrorMessage, e.APIErrorCode)
+	case e.APIErrorMessage != "" || e.APIErrorCode != "":
+		msg += ": " + e.APIErrorMessage + e.APIErrorCode
+	case e.Body != "":
+		msg += fmt.Sprintf(": %q", e.Body)
+	case len(e.Violations) > 0:
+		msg += ": " + formatViolations(e.Violations)
 	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
 }
 
-const (
+// readAPIError fills the API error fields from an error response body (decoded as ApiError or kept as a capped raw snippet)
+func (e *AccountError) readAPIError(body io.Reader) {
+	b, err := io.ReadAll(io.LimitReader(body, maxErrorBodySize+1))
+	if err != nil || len(b) == 0 {
+		return
+	}
+
+	apiErr := &types.APIErrorResponse{}
+	if _, err := apiErr.ReadFrom(bytes.NewReader(b)); err == nil {
+		e.APIErrorMessage, e.APIErrorCode = apiErr.Message()
+		if e.APIErrorMessage != "" || e.APIErrorCode != "" {
+			return
+		}
+	}
+
+	if len(b) > maxErrorBodySize {
+		e.Body = strings.TrimSpace(string(b[:maxErrorBodySize])) + "..."
+		return
+	}
+	e.Body = strings.TrimSpace(string(b))
+}
+
+// Unwrap returns the underlying error
+func (e *AccountError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is the kind of the error
+func (e *AccountError) Is(target error) bool {
+	return target == e.Kind
+}
+
+var (
 	// ErrNoRequest on missing request
-	ErrNoRequest = "no request provided"
+	ErrNoRequest = errors.New("no request provided")
 
 	// ErrInvalidBody on invalid request body
-	ErrInvalidBody = "invalid request body"
+	ErrInvalidBody = errors.New("invalid request body")
 
 	// ErrInvalidRequest on invalid request
-	ErrInvalidRequest = "invalid request"
+	ErrInvalidRequest = errors.New("invalid request")
+
+	// ErrValidation on request rejected by the client side validation (see AccountError.Violations)
+	ErrValidation = errors.New("validation failed")
+
+	// ErrInvalidTransition on account status change not allowed from the current status
+	ErrInvalidTransition = errors.New("invalid status transition")
 
 	// ErrDoRequest on request failed
-	ErrDoRequest = "request failed"
+	ErrDoRequest = errors.New("request failed")
+
+	// ErrAuthentication on failure to get the access token of the request
+	ErrAuthentication = errors.New("authentication failed")
+
+	// ErrCancelled on request cancelled or deadline exceeded by its context
+	ErrCancelled = errors.New("request cancelled")
 
 	// ErrAPIFailure on API failure
-	ErrAPIFailure = "api failure"
+	ErrAPIFailure = errors.New("api failure")
+
+	// ErrVersionConflict on version mismatch (account changed since it was fetched)
+	ErrVersionConflict = errors.New("version conflict")
+
+	// ErrConflictingDuplicate on idempotent create of an account which already exists with other attributes
+	ErrConflictingDuplicate = errors.New("conflicting duplicate")
 
 	// ErrInvalidResponse on invalid response
-	ErrInvalidResponse = "invalid response"
+	ErrInvalidResponse = errors.New("invalid response")
 )
@@ -0,0 +1,155 @@
+package accountclient_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/localhost418/accountclient"
+	"github.com/localhost418/accountclient/types"
+)
+
+func TestAccountError(t *testing.T) {
+	tt := []struct {
+		name   string
+		err    *accountclient.AccountError
+		kind   error
+		cause  error
+		status int
+		msg    string
+	}{
+		{
+			name: "no request",
+			err:  accountclient.NewAccountError(accountclient.ErrNoRequest, "GET", "v1/organisation/accounts", 0, nil),
+			kind: accountclient.ErrNoRequest,
+			msg:  "'GET v1/organisation/accounts': no request provided",
+		},
+		{
+			name:   "api failure",
+			err:    accountclient.NewAccountError(accountclient.ErrAPIFailure, "POST", "v1/organisation/accounts", 400, nil),
+			kind:   accountclient.ErrAPIFailure,
+			status: 400,
+			msg:    "'POST v1/organisation/accounts': api failure (status 400)",
+		},
+		{
+			name:  "cancelled",
+			err:   accountclient.NewAccountError(accountclient.ErrCancelled, "DELETE", "v1/organisation/accounts", 0, context.Canceled),
+			kind:  accountclient.ErrCancelled,
+			cause: context.Canceled,
+			msg:   "'DELETE v1/organisation/accounts': request cancelled: context canceled",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var err error = fmt.Errorf("wrapped: %w", tc.err)
+
+			if !errors.Is(err, tc.kind) {
+				t.Fatalf("errors.Is(%v, %v) is false", err, tc.kind)
+			}
+			if errors.Is(err, accountclient.ErrInvalidResponse) {
+				t.Fatalf("errors.Is(%v, %v) is true", err, accountclient.ErrInvalidResponse)
+			}
+			if tc.cause != nil && !errors.Is(err, tc.cause) {
+				t.Fatalf("errors.Is(%v, %v) is false", err, tc.cause)
+			}
+
+			var accErr *accountclient.AccountError
+			if !errors.As(err, &accErr) {
+				t.Fatalf("errors.As(%v) is false", err)
+			}
+			if accErr.StatusCode != tc.status {
+				t.Fatalf("wrong status code: want %d got %d", tc.status, accErr.StatusCode)
+			}
+			if accErr.Error() != tc.msg {
+				t.Fatalf("wrong error message:\n want %s\n got %s", tc.msg, accErr.Error())
+			}
+		})
+	}
+}
+
+func TestClientAPIError(t *testing.T) {
+	tt := []struct {
+		name    string
+		status  int
+		res     string
+		message string
+		code    string
+		body    string
+	}{
+		{
+			name:    "api error",
+			status:  http.StatusConflict,
+			res:     `{"error_message":"Account cannot be created as it violates a duplicate constraint","error_code":"2df52024-8b8d-4dc1-b713-1f6d8aa6ae48"}`,
+			message: "Account cannot be created as it violates a duplicate constraint",
+			code:    "2df52024-8b8d-4dc1-b713-1f6d8aa6ae48",
+		},
+		{
+			name:    "json api errors",
+			status:  http.StatusBadRequest,
+			res:     `{"errors":[{"status":"400","code":"invalid_country","title":"Bad request","detail":"country must be a valid ISO 3166-1 code"}]}`,
+			message: "country must be a valid ISO 3166-1 code",
+			code:    "invalid_country",
+		},
+		{
+			name:   "raw body",
+			status: http.StatusBadGateway,
+			res:    "<html>bad gateway</html>\n",
+			body:   "<html>bad gateway</html>",
+		},
+		{
+			name:   "capped raw body",
+			status: http.StatusInternalServerError,
+			res:    strings.Repeat("x", 2000),
+			body:   strings.Repeat("x", 1024) + "...",
+		},
+		{
+			name:   "empty body",
+			status: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				defer r.Body.Close()
+				w.WriteHeader(tc.status)
+				w.Write([]byte(tc.res))
+			})
+
+			srv := httptest.NewServer(handler)
+			defer srv.Close()
+
+			serverURL, err := url.Parse(srv.URL)
+			if err != nil {
+				t.Fatalf("cannot build server url: %s", err)
+			}
+
+			cli := accountclient.NewClient(&http.Client{Timeout: time.Second}, *serverURL)
+
+			_, err = cli.CreateAccount(&types.CreateAccountRequest{})
+			var accErr *accountclient.AccountError
+			if !errors.As(err, &accErr) || !errors.Is(err, accountclient.ErrAPIFailure) {
+				t.Fatalf("unexpected error %v ; expected %v", err, accountclient.ErrAPIFailure)
+			}
+			if accErr.StatusCode != tc.status {
+				t.Fatalf("wrong status code: want %d got %d", tc.status, accErr.StatusCode)
+			}
+			if accErr.APIErrorMessage != tc.message || accErr.APIErrorCode != tc.code {
+				t.Fatalf("wrong api error: want %q [%q] got %q [%q]", tc.message, tc.code, accErr.APIErrorMessage, accErr.APIErrorCode)
+			}
+			if accErr.Body != tc.body {
+				t.Fatalf("wrong body:\n want %q\n got %q", tc.body, accErr.Body)
+			}
+			if tc.message != "" && !strings.Contains(accErr.Error(), tc.message) {
+				t.Fatalf("'%s' not found in '%s'", tc.message, accErr.Error())
+			}
+		})
+	}
+}
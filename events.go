@@ -0,0 +1,166 @@
+package accountclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/localhost418/accountclient/types"
+)
+
+const (
+	// defaultTailInterval is the delay between two polls of a tail having caught up with the events
+	defaultTailInterval = 5 * time.Second
+	// defaultTailPageSize is the number of events fetched by each poll of a tail
+	defaultTailPageSize = 100
+)
+
+// FetchAccountEvents fetches a page of the events of an account
+func (c *Client) FetchAccountEvents(req *types.FetchAccountEventsRequest) (*types.FetchAccountEventsResponse, error) {
+	return c.FetchAccountEventsWithContext(context.Background(), req)
+}
+
+// FetchAccountEventsWithContext fetches a page of the events of an account, bound to ctx
+func (c *Client) FetchAccountEventsWithContext(ctx context.Context, req *types.FetchAccountEventsRequest) (*types.FetchAccountEventsResponse, error) {
+	const method = http.MethodGet
+	endpoint := c.accountsPath()
+	if req == nil {
+		return nil, NewAccountError(ErrNoRequest, method, endpoint, 0, nil)
+	}
+	if req.PageNumber < 0 || req.PageSize < 0 || req.PageSize > types.MaxPageSize {
+		err := fmt.Errorf("page number must be positive and page size between 0 and %d", types.MaxPageSize)
+		return nil, NewAccountError(ErrInvalidRequest, method, endpoint, 0, err)
+	}
+
+	res := &types.FetchAccountEventsResponse{}
+	err := c.send(ctx, &apiCall{
+		operation:  OperationFetchAccountEvents,
+		request:    req,
+		method:     method,
+		endpoint:   endpoint,
+		paths:      []string{endpoint, req.AccountID.String(), "events"},
+		query:      req.Query(),
+		status:     http.StatusOK,
+		idempotent: true,
+		response:   res,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// EventCursor is the position of a tail in the events of an account.
+// The caller persists the cursor of the last event it handled to resume the tail from there after a restart.
+type EventCursor struct {
+	// Offset is the number of events of the account already emitted
+	Offset int `json:"offset"`
+}
+
+// TailEvent is an event emitted by a tail with the cursor to resume the tail after it
+type TailEvent struct {
+	Event  *types.AccountEvent
+	Cursor EventCursor
+}
+
+// TailOptions configures an EventTail
+type TailOptions struct {
+	// Cursor to resume from (from the first event when zero)
+	Cursor EventCursor
+
+	// Interval between two polls once the tail has caught up (5s when zero)
+	Interval time.Duration
+
+	// PageSize is the number of events fetched by each poll (100 when zero, capped to types.MaxPageSize)
+	PageSize int
+}
+
+// EventTail polls the events of an account and emits the new ones on its channel, in order.
+//
+//	tail := cli.TailAccountEvents(ctx, accountID, &accountclient.TailOptions{Cursor: saved})
+//	for e := range tail.Events() {
+//		handle(e.Event)
+//		save(e.Cursor)
+//	}
+//	if err := tail.Err(); err != nil {
+//	}
+type EventTail struct {
+	events chan TailEvent
+	err    error
+}
+
+// TailAccountEvents starts tailing the events of accountID until ctx is done or a request fails (opts may be nil)
+func (c *Client) TailAccountEvents(ctx context.Context, accountID strfmt.UUID, opts *TailOptions) *EventTail {
+	var o TailOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.Interval <= 0 {
+		o.Interval = defaultTailInterval
+	}
+	if o.PageSize <= 0 {
+		o.PageSize = defaultTailPageSize
+	} else if o.PageSize > types.MaxPageSize {
+		o.PageSize = types.MaxPageSize
+	}
+
+	t := &EventTail{events: make(chan TailEvent)}
+	go func() {
+		defer close(t.events)
+		t.err = t.run(ctx, c, accountID, o)
+	}()
+	return t
+}
+
+// Events returns the channel of the new events, closed when the tail stops
+func (t *EventTail) Events() <-chan TailEvent {
+	return t.events
+}
+
+// Err returns the error which stopped the tail once Events is closed (nil when stopped by its context)
+func (t *EventTail) Err() error {
+	return t.err
+}
+
+// run polls the page holding the cursor, emits the events after it, and waits for the interval once caught up
+func (t *EventTail) run(ctx context.Context, c *Client, accountID strfmt.UUID, o TailOptions) error {
+	cursor := o.Cursor
+	for {
+		page := cursor.Offset / o.PageSize
+		res, err := c.FetchAccountEventsWithContext(ctx, &types.FetchAccountEventsRequest{
+			AccountID:  accountID,
+			PageNumber: page,
+			PageSize:   o.PageSize,
+		})
+		if err != nil {
+			if errors.Is(err, ErrCancelled) {
+				return nil
+			}
+			return err
+		}
+
+		for i := cursor.Offset - page*o.PageSize; i < len(res.Data); i++ {
+			cursor.Offset++
+			select {
+			case <-ctx.Done():
+				return nil
+			case t.events <- TailEvent{Event: res.Data[i], Cursor: cursor}:
+			}
+		}
+
+		// a full page means the next one may already hold events
+		if len(res.Data) >= o.PageSize {
+			continue
+		}
+		timer := time.NewTimer(o.Interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
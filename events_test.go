@@ -0,0 +1,211 @@
+package accountclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/localhost418/accountclient"
+	"github.com/localhost418/accountclient/types"
+)
+
+// eventsHandler serves the pages of a growing list of account events
+type eventsHandler struct {
+	mu     sync.Mutex
+	events []*types.AccountEvent
+	status int
+}
+
+func (h *eventsHandler) add(n int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i := 0; i < n; i++ {
+		h.events = append(h.events, &types.AccountEvent{
+			ID:   strfmt.UUID(fmt.Sprintf("00000000-0000-0000-0000-%012d", len(h.events))),
+			Type: "account_events",
+			Attributes: &types.AccountEventAttributes{
+				Status:        types.AccountEventConfirmed,
+				RoutingStatus: types.AccountRoutable,
+			},
+		})
+	}
+}
+
+func (h *eventsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.status != 0 {
+		w.WriteHeader(h.status)
+		return
+	}
+	number, _ := strconv.Atoi(r.URL.Query().Get("page[number]"))
+	size, _ := strconv.Atoi(r.URL.Query().Get("page[size]"))
+	if size == 0 {
+		size = 100
+	}
+	res := &types.FetchAccountEventsResponse{Data: []*types.AccountEvent{}}
+	for i := number * size; i < (number+1)*size && i < len(h.events); i++ {
+		res.Data = append(res.Data, h.events[i])
+	}
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+func TestClientFetchEventsRequest(t *testing.T) {
+	fakeURL, _ := url.Parse("")
+	tt := []struct {
+		name string
+		req  *types.FetchAccountEventsRequest
+		err  error
+	}{
+		{
+			name: "nil request",
+			req:  nil,
+			err:  accountclient.ErrNoRequest,
+		},
+		{
+			name: "page size too large",
+			req:  &types.FetchAccountEventsRequest{PageSize: types.MaxPageSize + 1},
+			err:  accountclient.ErrInvalidRequest,
+		},
+		{
+			name: "error do request",
+			req:  &types.FetchAccountEventsRequest{},
+			err:  accountclient.ErrDoRequest,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			cli := accountclient.NewClient(&http.Client{Timeout: time.Second}, *fakeURL)
+
+			_, err := cli.FetchAccountEvents(tc.req)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("unexpected error %v ; expected %v", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestClientFetchEventsResponse(t *testing.T) {
+	accountID := strfmt.UUID("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+	body := `{"data":[{"id":"6d4f9916-3af9-416e-a347-2d8df90fc4ab","type":"account_events","version":0,` +
+		`"attributes":{"account_id":"ad27e265-9605-4b4b-a0e5-3003ea9cc4dc","date_time":"2021-06-01T10:00:00.000Z",` +
+		`"status":"failed","description":"failed","reason":"Invalid BIC","routing_status":"unroutable"}}]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/organisation/accounts/"+accountID.String()+"/events" || r.URL.RawQuery != "page%5Bnumber%5D=2&page%5Bsize%5D=10" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+	serverURL, _ := url.Parse(server.URL)
+	cli := accountclient.NewClient(server.Client(), *serverURL)
+
+	res, err := cli.FetchAccountEvents(&types.FetchAccountEventsRequest{AccountID: accountID, PageNumber: 2, PageSize: 10})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(res.Data) != 1 {
+		t.Fatalf("wrong number of events %d", len(res.Data))
+	}
+	attrs := res.Data[0].Attributes
+	if attrs.Status != types.AccountEventFailed || attrs.RoutingStatus != types.AccountUnroutable || attrs.Reason != "Invalid BIC" || attrs.AccountID != accountID {
+		t.Fatalf("wrong event attributes %+v", attrs)
+	}
+}
+
+func TestClientTailEvents(t *testing.T) {
+	handler := &eventsHandler{}
+	handler.add(5)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	serverURL, _ := url.Parse(server.URL)
+	cli := accountclient.NewClient(server.Client(), *serverURL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	opts := &accountclient.TailOptions{Cursor: accountclient.EventCursor{Offset: 1}, Interval: 10 * time.Millisecond, PageSize: 2}
+	tail := cli.TailAccountEvents(ctx, "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", opts)
+
+	var cursor accountclient.EventCursor
+	for i := 1; i < 8; i++ {
+		if i == 5 {
+			// new events are emitted once the tail has caught up
+			handler.add(3)
+		}
+		e := <-tail.Events()
+		want := strfmt.UUID(fmt.Sprintf("00000000-0000-0000-0000-%012d", i))
+		if e.Event.ID != want || e.Cursor.Offset != i+1 {
+			t.Fatalf("wrong event: want %s (offset %d) got %s (offset %d)", want, i+1, e.Event.ID, e.Cursor.Offset)
+		}
+		cursor = e.Cursor
+	}
+	cancel()
+	for range tail.Events() {
+	}
+	if err := tail.Err(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// resume from the persisted cursor
+	handler.add(1)
+	tail = cli.TailAccountEvents(context.Background(), "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", &accountclient.TailOptions{Cursor: cursor, Interval: 10 * time.Millisecond, PageSize: 2})
+	e := <-tail.Events()
+	if e.Event.ID != "00000000-0000-0000-0000-000000000008" {
+		t.Fatalf("wrong event after resume %s", e.Event.ID)
+	}
+
+	// the tail stops on error
+	handler.mu.Lock()
+	handler.status = http.StatusNotFound
+	handler.mu.Unlock()
+	for range tail.Events() {
+	}
+	if err := tail.Err(); !errors.Is(err, accountclient.ErrAPIFailure) {
+		t.Fatalf("unexpected error %v ; expected %v", err, accountclient.ErrAPIFailure)
+	}
+}
+
+func TestClientTailEventsPageSize(t *testing.T) {
+	handler := &eventsHandler{}
+	handler.add(3)
+	sizes := make(chan string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case sizes <- r.URL.Query().Get("page[size]"):
+		default:
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	serverURL, _ := url.Parse(server.URL)
+	cli := accountclient.NewClient(server.Client(), *serverURL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	opts := &accountclient.TailOptions{Interval: 10 * time.Millisecond, PageSize: types.MaxPageSize + 1}
+	tail := cli.TailAccountEvents(ctx, "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", opts)
+	for i := 0; i < 3; i++ {
+		if _, ok := <-tail.Events(); !ok {
+			t.Fatalf("tail stopped after %d events: %v", i, tail.Err())
+		}
+	}
+	if size := <-sizes; size != strconv.Itoa(types.MaxPageSize) {
+		t.Fatalf("wrong page size %s ; expected %d", size, types.MaxPageSize)
+	}
+	cancel()
+	for range tail.Events() {
+	}
+	if err := tail.Err(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
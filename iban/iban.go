@@ -0,0 +1,173 @@
+// Package iban validates and derives International Bank Account Numbers (ISO 13616)
+package iban
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// ErrInvalidFormat on an iban with characters other than letters and digits or a malformed country and check digits
+	ErrInvalidFormat = errors.New("invalid iban format")
+
+	// ErrUnsupportedCountry on a country without a known iban structure
+	ErrUnsupportedCountry = errors.New("unsupported iban country")
+
+	// ErrInvalidLength on an iban of the wrong length for its country
+	ErrInvalidLength = errors.New("invalid iban length")
+
+	// ErrInvalidBBAN on a basic bank account number not matching the structure of its country
+	ErrInvalidBBAN = errors.New("invalid iban bban")
+
+	// ErrInvalidChecksum on an iban failing the mod-97 check
+	ErrInvalidChecksum = errors.New("invalid iban checksum")
+
+	// ErrMissingField on a field needed to derive an iban not provided
+	ErrMissingField = errors.New("missing field to derive iban")
+)
+
+// structure is the length and BBAN format of the ibans of a country
+type structure struct {
+	length int
+	bban   *regexp.Regexp
+}
+
+// structures by country, see the SWIFT IBAN registry
+var structures = map[string]structure{
+	"AT": {20, regexp.MustCompile(`^[0-9]{16}$`)},
+	"BE": {16, regexp.MustCompile(`^[0-9]{12}$`)},
+	"CH": {21, regexp.MustCompile(`^[0-9]{5}[A-Z0-9]{12}$`)},
+	"DE": {22, regexp.MustCompile(`^[0-9]{18}$`)},
+	"ES": {24, regexp.MustCompile(`^[0-9]{20}$`)},
+	"FR": {27, regexp.MustCompile(`^[0-9]{10}[A-Z0-9]{11}[0-9]{2}$`)},
+	"GB": {22, regexp.MustCompile(`^[A-Z]{4}[0-9]{14}$`)},
+	"GR": {27, regexp.MustCompile(`^[0-9]{7}[A-Z0-9]{16}$`)},
+	"IE": {22, regexp.MustCompile(`^[A-Z]{4}[0-9]{14}$`)},
+	"IT": {27, regexp.MustCompile(`^[A-Z][0-9]{10}[A-Z0-9]{12}$`)},
+	"LU": {20, regexp.MustCompile(`^[0-9]{3}[A-Z0-9]{13}$`)},
+	"NL": {18, regexp.MustCompile(`^[A-Z]{4}[0-9]{10}$`)},
+	"PL": {28, regexp.MustCompile(`^[0-9]{24}$`)},
+	"PT": {25, regexp.MustCompile(`^[0-9]{21}$`)},
+}
+
+var format = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z0-9]+$`)
+
+// minLength and maxLength bound the length of the ibans of the countries without a known structure
+const (
+	minLength = 15
+	maxLength = 34
+)
+
+// Normalize removes the spaces of iban and upper cases it
+func Normalize(iban string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(iban), ""))
+}
+
+// Validate checks the structure of iban for its country and its mod-97 checksum, iban must be normalized.
+// The ibans of the countries without a known structure are only checked for their length, characters and checksum.
+func Validate(iban string) error {
+	if !format.MatchString(iban) {
+		return ErrInvalidFormat
+	}
+	country := iban[:2]
+	if s, ok := structures[country]; ok {
+		if len(iban) != s.length {
+			return fmt.Errorf("%w: %d characters expected in %s", ErrInvalidLength, s.length, country)
+		}
+		if !s.bban.MatchString(iban[4:]) {
+			return fmt.Errorf("%w for %s", ErrInvalidBBAN, country)
+		}
+	} else if len(iban) < minLength || len(iban) > maxLength {
+		return fmt.Errorf("%w: %d to %d characters expected", ErrInvalidLength, minLength, maxLength)
+	}
+	if mod97(iban[4:]+iban[:4]) != 1 {
+		return ErrInvalidChecksum
+	}
+	return nil
+}
+
+// BBAN returns the basic bank account number of a valid iban
+func BBAN(iban string) string {
+	return iban[4:]
+}
+
+// Build makes the iban of country and bban by computing its check digits
+func Build(country, bban string) (string, error) {
+	country = strings.ToUpper(country)
+	s, ok := structures[country]
+	if !ok {
+		return "", fmt.Errorf("%w %s", ErrUnsupportedCountry, country)
+	}
+	if !s.bban.MatchString(bban) {
+		return "", fmt.Errorf("%w for %s", ErrInvalidBBAN, country)
+	}
+	check := 98 - mod97(bban+country+"00")
+	return fmt.Sprintf("%s%02d%s", country, check, bban), nil
+}
+
+// Derive makes the iban of an account from its country, bank_id, account_number and bic (the bank code of GB, IE and NL ibans)
+func Derive(country, bankID, accountNumber, bic string) (string, error) {
+	country = strings.ToUpper(country)
+	if accountNumber == "" {
+		return "", fmt.Errorf("%w account_number", ErrMissingField)
+	}
+	var bban string
+	switch country {
+	case "GB", "IE":
+		if len(bic) < 4 {
+			return "", fmt.Errorf("%w bic", ErrMissingField)
+		}
+		if bankID == "" {
+			return "", fmt.Errorf("%w bank_id", ErrMissingField)
+		}
+		bban = bic[:4] + bankID + accountNumber
+	case "NL":
+		if len(bic) < 4 {
+			return "", fmt.Errorf("%w bic", ErrMissingField)
+		}
+		bban = bic[:4] + leftPad(accountNumber, 10)
+	case "DE":
+		if bankID == "" {
+			return "", fmt.Errorf("%w bank_id", ErrMissingField)
+		}
+		bban = bankID + leftPad(accountNumber, 10)
+	case "BE":
+		if bankID == "" {
+			return "", fmt.Errorf("%w bank_id", ErrMissingField)
+		}
+		// the national check digits are the remainder of the bank and account numbers by 97 (97 instead of 0)
+		bban = bankID + accountNumber
+		key := mod97(bban)
+		if key == 0 {
+			key = 97
+		}
+		bban += fmt.Sprintf("%02d", key)
+	default:
+		return "", fmt.Errorf("%w %s", ErrUnsupportedCountry, country)
+	}
+	return Build(country, bban)
+}
+
+// mod97 computes the remainder by 97 of s with letters replaced by 10 (A) to 35 (Z)
+func mod97(s string) int {
+	r := 0
+	for _, c := range s {
+		switch {
+		case c >= '0' && c <= '9':
+			r = (r*10 + int(c-'0')) % 97
+		case c >= 'A' && c <= 'Z':
+			r = (r*100 + int(c-'A') + 10) % 97
+		}
+	}
+	return r
+}
+
+// leftPad pads s with zeros to n characters
+func leftPad(s string, n int) string {
+	if len(s) >= n {
+		return s
+	}
+	return strings.Repeat("0", n-len(s)) + s
+}
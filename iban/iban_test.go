@@ -0,0 +1,96 @@
+package iban_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/localhost418/accountclient/iban"
+)
+
+func TestValidate(t *testing.T) {
+	tt := []struct {
+		iban string
+		err  error
+	}{
+		{iban: "AT611904300234573201"},
+		{iban: "BE68539007547034"},
+		{iban: "CH9300762011623852957"},
+		{iban: "DE89370400440532013000"},
+		{iban: "ES9121000418450200051332"},
+		{iban: "FR1420041010050500013M02606"},
+		{iban: "GB29NWBK60161331926819"},
+		{iban: "GR1601101250000000012300695"},
+		{iban: "IE29AIBK93115212345678"},
+		{iban: "IT60X0542811101000000123456"},
+		{iban: "LU280019400644750000"},
+		{iban: "NL91ABNA0417164300"},
+		{iban: "PL61109010140000071219812874"},
+		{iban: "PT50000201231234567890154"},
+		{iban: "GB29NWBK60161331926818", err: iban.ErrInvalidChecksum},
+		{iban: "GB29NWBK6016133192681", err: iban.ErrInvalidLength},
+		{iban: "GB29NWB160161331926819", err: iban.ErrInvalidBBAN},
+		{iban: "DK5000400440116243"},
+		{iban: "FI2112345600000785"},
+		{iban: "NO9386011117947"},
+		{iban: "SE4550000000058398257466"},
+		{iban: "SE4550000000058398257467", err: iban.ErrInvalidChecksum},
+		{iban: "NO938601111794", err: iban.ErrInvalidLength},
+		{iban: "ZZ29NWBK60161331926819", err: iban.ErrInvalidChecksum},
+		{iban: "gb29nwbk60161331926819", err: iban.ErrInvalidFormat},
+		{iban: "GB2", err: iban.ErrInvalidFormat},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.iban, func(t *testing.T) {
+			err := iban.Validate(tc.iban)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("unexpected error %v ; expected %v", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	if got := iban.Normalize(" gb29 NWBK 6016 1331 9268 19 "); got != "GB29NWBK60161331926819" {
+		t.Fatalf("wrong normalized iban %s", got)
+	}
+}
+
+func TestDerive(t *testing.T) {
+	tt := []struct {
+		country       string
+		bankID        string
+		accountNumber string
+		bic           string
+		iban          string
+		err           error
+	}{
+		{country: "GB", bankID: "601613", accountNumber: "31926819", bic: "NWBKGB22", iban: "GB29NWBK60161331926819"},
+		{country: "IE", bankID: "931152", accountNumber: "12345678", bic: "AIBKIE2D", iban: "IE29AIBK93115212345678"},
+		{country: "NL", accountNumber: "417164300", bic: "ABNANL2A", iban: "NL91ABNA0417164300"},
+		{country: "DE", bankID: "37040044", accountNumber: "532013000", iban: "DE89370400440532013000"},
+		{country: "BE", bankID: "539", accountNumber: "0075470", iban: "BE68539007547034"},
+		{country: "GB", bankID: "601613", accountNumber: "31926819", err: iban.ErrMissingField},
+		{country: "DE", accountNumber: "532013000", err: iban.ErrMissingField},
+		{country: "GB", bankID: "601613", bic: "NWBKGB22", err: iban.ErrMissingField},
+		{country: "GB", bankID: "6016", accountNumber: "31926819", bic: "NWBKGB22", err: iban.ErrInvalidBBAN},
+		{country: "US", bankID: "021000021", accountNumber: "123456789", err: iban.ErrUnsupportedCountry},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.country+tc.iban, func(t *testing.T) {
+			got, err := iban.Derive(tc.country, tc.bankID, tc.accountNumber, tc.bic)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("unexpected error %v ; expected %v", err, tc.err)
+			}
+			if got != tc.iban {
+				t.Fatalf("wrong iban: want %s got %s", tc.iban, got)
+			}
+			if got != "" {
+				if err := iban.Validate(got); err != nil {
+					t.Fatalf("derived iban %s is invalid: %v", got, err)
+				}
+			}
+		})
+	}
+}
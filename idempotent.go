@@ -0,0 +1,126 @@
+package accountclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/localhost418/accountclient/generated/models"
+	"github.com/localhost418/accountclient/types"
+)
+
+// AccountIDFromKey derives the ID of the account of a business key (e.g. a customer reference) in an organisation,
+// as the name based UUID (version 5) of key in the organisation ID namespace
+func AccountIDFromKey(organisationID strfmt.UUID, key string) (strfmt.UUID, error) {
+	return newNameUUID(organisationID, key)
+}
+
+// CreateAccountIdempotent creates the account of a business key, see CreateAccountIdempotentWithContext
+func (c *Client) CreateAccountIdempotent(key string, req *types.CreateAccountRequest) (*types.CreateAccountResponse, error) {
+	return c.CreateAccountIdempotentWithContext(context.Background(), key, req)
+}
+
+/*
+CreateAccountIdempotentWithContext creates the account of a business key, bound to ctx, so a create can be retried safely.
+The account ID is derived from key and the organisation ID (see AccountIDFromKey), a request ID other than this one is rejected.
+An empty key uses the account ID of the request instead, which must then be set.
+When the account already exists (409) it is fetched: it is returned, with Existing set, if it has the attributes of the request,
+otherwise the error is of kind ErrConflictingDuplicate.
+*/
+func (c *Client) CreateAccountIdempotentWithContext(ctx context.Context, key string, req *types.CreateAccountRequest) (*types.CreateAccountResponse, error) {
+	const method = http.MethodPost
+	endpoint := c.accountsPath()
+	if req == nil || req.Data == nil {
+		return nil, NewAccountError(ErrNoRequest, method, endpoint, 0, nil)
+	}
+	if key == "" && req.Data.ID == nil {
+		return nil, NewAccountError(ErrInvalidRequest, method, endpoint, 0, errors.New("empty business key"))
+	}
+
+	organisationID := c.organisationID
+	if req.Data.OrganisationID != nil {
+		organisationID = *req.Data.OrganisationID
+	}
+	if organisationID == "" {
+		return nil, NewAccountError(ErrInvalidRequest, method, endpoint, 0, errors.New("no organisation id to derive the account id"))
+	}
+	var id strfmt.UUID
+	if key == "" {
+		id = *req.Data.ID
+	} else {
+		derived, err := AccountIDFromKey(organisationID, key)
+		if err != nil {
+			return nil, NewAccountError(ErrInvalidRequest, method, endpoint, 0, err)
+		}
+		id = derived
+	}
+	if req.Data.ID != nil && *req.Data.ID != id {
+		err := fmt.Errorf("account id %s is not the id %s derived from the business key", *req.Data.ID, id)
+		return nil, NewAccountError(ErrInvalidRequest, method, endpoint, 0, err)
+	}
+
+	// copy so the caller request is left untouched
+	data := *req.Data
+	data.ID = &id
+	data.OrganisationID = &organisationID
+	copied := *req
+	copied.Data = &data
+
+	res, err := c.CreateAccountWithContext(ctx, &copied)
+	var accErr *AccountError
+	if err == nil || !errors.As(err, &accErr) || accErr.StatusCode != http.StatusConflict {
+		return res, err
+	}
+
+	fetched, fetchErr := c.FetchAccountWithContext(ctx, &types.FetchAccountRequest{AccountID: id})
+	if fetchErr != nil {
+		return nil, fetchErr
+	}
+	if fetched.Data == nil || !sameAccount(&data, fetched.Data) {
+		err := fmt.Errorf("account %s already exists with other attributes", id)
+		return nil, NewAccountError(ErrConflictingDuplicate, method, endpoint, http.StatusConflict, err)
+	}
+	return &types.CreateAccountResponse{Data: fetched.Data, Links: fetched.Links, Existing: true}, nil
+}
+
+// sameAccount tells whether existing has the organisation and every attribute set in requested
+// (the API may add attributes to the created account, e.g. a generated iban)
+func sameAccount(requested, existing *models.Account) bool {
+	if existing.OrganisationID == nil || *existing.OrganisationID != *requested.OrganisationID {
+		return false
+	}
+	want, err := attributesMap(requested.Attributes)
+	if err != nil {
+		return false
+	}
+	got, err := attributesMap(existing.Attributes)
+	if err != nil {
+		return false
+	}
+	for name, value := range want {
+		if value == nil {
+			continue
+		}
+		if !reflect.DeepEqual(got[name], value) {
+			return false
+		}
+	}
+	return true
+}
+
+// attributesMap decodes the JSON of attrs as a map by attribute name
+func attributesMap(attrs *models.AccountAttributes) (map[string]interface{}, error) {
+	m := map[string]interface{}{}
+	if attrs == nil {
+		return m, nil
+	}
+	b, err := json.Marshal(attrs)
+	if err != nil {
+		return nil, err
+	}
+	return m, json.Unmarshal(b, &m)
+}
@@ -0,0 +1,109 @@
+package accountclient_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/localhost418/accountclient"
+	"github.com/localhost418/accountclient/accountapitest"
+	"github.com/localhost418/accountclient/generated/models"
+	"github.com/localhost418/accountclient/types"
+)
+
+func TestAccountIDFromKey(t *testing.T) {
+	// name based UUID of python.org in the DNS namespace (RFC 4122)
+	id, err := accountclient.AccountIDFromKey("6ba7b810-9dad-11d1-80b4-00c04fd430c8", "python.org")
+	if err != nil || id != "886313e1-3b8a-5372-9b90-0c9aee199e5d" {
+		t.Fatalf("wrong id %s (error %v)", id, err)
+	}
+
+	other, _ := accountclient.AccountIDFromKey("eb0bd6f5-c3f5-44b2-b677-acd23cdde73c", "python.org")
+	if other == id || !strfmt.IsUUID(other.String()) {
+		t.Fatalf("wrong id %s in another organisation", other)
+	}
+
+	if _, err := accountclient.AccountIDFromKey("organisation", "python.org"); err == nil {
+		t.Fatalf("expected an error for an invalid namespace")
+	}
+}
+
+func TestClientCreateAccountIdempotent(t *testing.T) {
+	organisationID := "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c"
+	newRequest := func(name string) *types.CreateAccountRequest {
+		country := "GB"
+		return &types.CreateAccountRequest{Data: &models.Account{
+			Type:       "accounts",
+			Attributes: &models.AccountAttributes{Country: &country, Name: []string{name}},
+		}}
+	}
+
+	srv := accountapitest.NewServer()
+	defer srv.Close()
+	cli, err := accountclient.NewClientWithOptions(srv.URL, accountclient.WithOrganisationID(organisationID))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	req := newRequest("Jane Doe")
+	created, err := cli.CreateAccountIdempotent("customer-42", req)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	want, _ := accountclient.AccountIDFromKey(strfmt.UUID(organisationID), "customer-42")
+	if *created.Data.ID != want || req.Data.ID != nil || created.Existing {
+		t.Fatalf("wrong account id %s", *created.Data.ID)
+	}
+
+	// retrying the create returns the existing account
+	again, err := cli.CreateAccountIdempotent("customer-42", newRequest("Jane Doe"))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if *again.Data.ID != want || !again.Existing || len(srv.Accounts()) != 1 {
+		t.Fatalf("account created twice")
+	}
+
+	// without key, the account ID of the request is used
+	withID := newRequest("Jane Doe")
+	withID.Data.ID = &want
+	if res, err := cli.CreateAccountIdempotent("", withID); err != nil || !res.Existing {
+		t.Fatalf("unexpected response %+v (error %v)", res, err)
+	}
+
+	_, err = cli.CreateAccountIdempotent("customer-42", newRequest("John Doe"))
+	var accErr *accountclient.AccountError
+	if !errors.Is(err, accountclient.ErrConflictingDuplicate) || !errors.As(err, &accErr) || accErr.StatusCode != http.StatusConflict {
+		t.Fatalf("unexpected error %v ; expected %v", err, accountclient.ErrConflictingDuplicate)
+	}
+
+	other := strfmt.UUID("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+	mismatch := newRequest("Jane Doe")
+	mismatch.Data.ID = &other
+	if _, err := cli.CreateAccountIdempotent("customer-42", mismatch); !errors.Is(err, accountclient.ErrInvalidRequest) {
+		t.Fatalf("unexpected error %v ; expected %v", err, accountclient.ErrInvalidRequest)
+	}
+	if _, err := cli.CreateAccountIdempotent("", newRequest("Jane Doe")); !errors.Is(err, accountclient.ErrInvalidRequest) {
+		t.Fatalf("unexpected error %v ; expected %v", err, accountclient.ErrInvalidRequest)
+	}
+}
+
+func TestClientCreateAccountIdempotentNoOrganisation(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	}))
+	defer server.Close()
+	cli, err := accountclient.NewClientWithOptions(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	_, err = cli.CreateAccountIdempotent("customer-42", &types.CreateAccountRequest{Data: &models.Account{}})
+	if !errors.Is(err, accountclient.ErrInvalidRequest) || calls != 0 {
+		t.Fatalf("unexpected error %v ; expected %v", err, accountclient.ErrInvalidRequest)
+	}
+}
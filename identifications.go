@@ -0,0 +1,163 @@
+package accountclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+
+	"github.com/localhost418/accountclient/types"
+)
+
+// identificationsPath is the path of the identifications of an account, under the account path
+const identificationsPath = "identifications"
+
+// CreateAccountIdentification attaches an identification to an existing account
+func (c *Client) CreateAccountIdentification(req *types.CreateAccountIdentificationRequest) (*types.CreateAccountIdentificationResponse, error) {
+	return c.CreateAccountIdentificationWithContext(context.Background(), req)
+}
+
+// CreateAccountIdentificationWithContext attaches an identification to an existing account, bound to ctx
+func (c *Client) CreateAccountIdentificationWithContext(ctx context.Context, req *types.CreateAccountIdentificationRequest) (*types.CreateAccountIdentificationResponse, error) {
+	const method = http.MethodPost
+	endpoint := c.accountsPath()
+	if req == nil {
+		return nil, NewAccountError(ErrNoRequest, method, endpoint, 0, nil)
+	}
+	if req.Data != nil && req.Data.OrganisationID == "" && c.organisationID != "" {
+		// copy so the caller request is left untouched
+		data := *req.Data
+		data.OrganisationID = c.organisationID
+		req = &types.CreateAccountIdentificationRequest{AccountID: req.AccountID, Data: &data}
+	}
+
+	res := &types.CreateAccountIdentificationResponse{}
+	call := &apiCall{
+		operation: OperationCreateAccountIdentification,
+		request:   req,
+		method:    method,
+		endpoint:  endpoint,
+		paths:     []string{endpoint, req.AccountID.String(), identificationsPath},
+		body:      req,
+		status:    http.StatusCreated,
+		// as for accounts, a second POST with the same identification ID is rejected
+		idempotent: req.Data != nil && req.Data.ID != "",
+		response:   res,
+	}
+	err := c.send(ctx, call)
+	if err != nil && call.idempotent && call.attempts > 1 && err.StatusCode == http.StatusConflict {
+		return c.replayedIdentification(ctx, req, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// replayedIdentification resolves the 409 of a retried create, the identification being possibly created by an attempt whose
+// response was lost: the existing identification is returned if it has the organisation and attributes of req, otherwise conflict is
+func (c *Client) replayedIdentification(ctx context.Context, req *types.CreateAccountIdentificationRequest, conflict *AccountError) (*types.CreateAccountIdentificationResponse, error) {
+	fetched, err := c.FetchAccountIdentificationWithContext(ctx, &types.FetchAccountIdentificationRequest{AccountID: req.AccountID, IdentificationID: req.Data.ID})
+	if err != nil || fetched.Data == nil || fetched.Data.OrganisationID != req.Data.OrganisationID ||
+		!reflect.DeepEqual(fetched.Data.Attributes, req.Data.Attributes) {
+		return nil, conflict
+	}
+	return &types.CreateAccountIdentificationResponse{Data: fetched.Data, Links: fetched.Links}, nil
+}
+
+// FetchAccountIdentification fetches an identification of an account by accountID and identificationID
+func (c *Client) FetchAccountIdentification(req *types.FetchAccountIdentificationRequest) (*types.FetchAccountIdentificationResponse, error) {
+	return c.FetchAccountIdentificationWithContext(context.Background(), req)
+}
+
+// FetchAccountIdentificationWithContext fetches an identification of an account by accountID and identificationID, bound to ctx
+func (c *Client) FetchAccountIdentificationWithContext(ctx context.Context, req *types.FetchAccountIdentificationRequest) (*types.FetchAccountIdentificationResponse, error) {
+	const method = http.MethodGet
+	endpoint := c.accountsPath()
+	if req == nil {
+		return nil, NewAccountError(ErrNoRequest, method, endpoint, 0, nil)
+	}
+
+	res := &types.FetchAccountIdentificationResponse{}
+	err := c.send(ctx, &apiCall{
+		operation:  OperationFetchAccountIdentification,
+		request:    req,
+		method:     method,
+		endpoint:   endpoint,
+		paths:      []string{endpoint, req.AccountID.String(), identificationsPath, req.IdentificationID.String()},
+		status:     http.StatusOK,
+		idempotent: true,
+		response:   res,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// DeleteAccountIdentification deletes an identification of an account by accountID, identificationID and version
+func (c *Client) DeleteAccountIdentification(req *types.DeleteAccountIdentificationRequest) (*types.DeleteAccountIdentificationResponse, error) {
+	return c.DeleteAccountIdentificationWithContext(context.Background(), req)
+}
+
+// DeleteAccountIdentificationWithContext deletes an identification of an account by accountID, identificationID and version, bound to ctx
+func (c *Client) DeleteAccountIdentificationWithContext(ctx context.Context, req *types.DeleteAccountIdentificationRequest) (*types.DeleteAccountIdentificationResponse, error) {
+	const method = http.MethodDelete
+	endpoint := c.accountsPath()
+	if req == nil {
+		return nil, NewAccountError(ErrNoRequest, method, endpoint, 0, nil)
+	}
+
+	err := c.send(ctx, &apiCall{
+		operation:  OperationDeleteAccountIdentification,
+		request:    req,
+		method:     method,
+		endpoint:   endpoint,
+		paths:      []string{endpoint, req.AccountID.String(), identificationsPath, req.IdentificationID.String()},
+		query:      url.Values{"version": []string{strconv.Itoa(req.Version)}},
+		status:     http.StatusNoContent,
+		conflict:   true,
+		idempotent: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &types.DeleteAccountIdentificationResponse{}, nil
+}
+
+// ListAccountIdentifications lists a page of the identifications of an account matching the request filters
+func (c *Client) ListAccountIdentifications(req *types.ListAccountIdentificationsRequest) (*types.ListAccountIdentificationsResponse, error) {
+	return c.ListAccountIdentificationsWithContext(context.Background(), req)
+}
+
+// ListAccountIdentificationsWithContext lists a page of the identifications of an account matching the request filters, bound to ctx
+func (c *Client) ListAccountIdentificationsWithContext(ctx context.Context, req *types.ListAccountIdentificationsRequest) (*types.ListAccountIdentificationsResponse, error) {
+	const method = http.MethodGet
+	endpoint := c.accountsPath()
+	if req == nil {
+		return nil, NewAccountError(ErrNoRequest, method, endpoint, 0, nil)
+	}
+	if req.PageNumber < 0 || req.PageSize < 0 || req.PageSize > types.MaxPageSize {
+		err := fmt.Errorf("page number must be positive and page size between 0 and %d", types.MaxPageSize)
+		return nil, NewAccountError(ErrInvalidRequest, method, endpoint, 0, err)
+	}
+
+	res := &types.ListAccountIdentificationsResponse{}
+	err := c.send(ctx, &apiCall{
+		operation:  OperationListAccountIdentifications,
+		request:    req,
+		method:     method,
+		endpoint:   endpoint,
+		paths:      []string{endpoint, req.AccountID.String(), identificationsPath},
+		query:      req.Query(),
+		status:     http.StatusOK,
+		idempotent: true,
+		response:   res,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,271 @@
+package accountclient_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/localhost418/accountclient"
+	"github.com/localhost418/accountclient/types"
+)
+
+// identificationsStub stores the identifications of a single account
+type identificationsStub struct {
+	mu              sync.Mutex
+	prefix          string
+	identifications []*types.AccountIdentification
+}
+
+func (s *identificationsStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !strings.HasPrefix(r.URL.Path, s.prefix) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	id := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, s.prefix), "/")
+
+	switch {
+	case id == "" && r.Method == http.MethodPost:
+		req := &types.CreateAccountIdentificationRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		for _, i := range s.identifications {
+			if i.Attributes.SecondaryIdentification == req.Data.Attributes.SecondaryIdentification {
+				w.WriteHeader(http.StatusConflict)
+				_, _ = w.Write([]byte(`{"error_message":"secondary identification already used"}`))
+				return
+			}
+		}
+		version := int64(0)
+		req.Data.Version = &version
+		s.identifications = append(s.identifications, req.Data)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(&types.CreateAccountIdentificationResponse{Data: req.Data})
+	case id == "" && r.Method == http.MethodGet:
+		res := &types.ListAccountIdentificationsResponse{Data: []*types.AccountIdentification{}}
+		filter := r.URL.Query().Get("filter[secondary_identification]")
+		for _, i := range s.identifications {
+			if filter == "" || filter == i.Attributes.SecondaryIdentification {
+				res.Data = append(res.Data, i)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(res)
+	default:
+		for n, i := range s.identifications {
+			if i.ID.String() != id {
+				continue
+			}
+			switch r.Method {
+			case http.MethodGet:
+				_ = json.NewEncoder(w).Encode(&types.FetchAccountIdentificationResponse{Data: i})
+			case http.MethodDelete:
+				if r.URL.Query().Get("version") != strconv.FormatInt(*i.Version, 10) {
+					w.WriteHeader(http.StatusConflict)
+					return
+				}
+				s.identifications = append(s.identifications[:n], s.identifications[n+1:]...)
+				w.WriteHeader(http.StatusNoContent)
+			}
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestClientIdentificationsRequest(t *testing.T) {
+	fakeURL, _ := url.Parse("")
+	cli := accountclient.NewClient(&http.Client{Timeout: time.Second}, *fakeURL)
+	tt := []struct {
+		name string
+		call func() error
+		err  error
+	}{
+		{
+			name: "create nil request",
+			call: func() error { _, err := cli.CreateAccountIdentification(nil); return err },
+			err:  accountclient.ErrNoRequest,
+		},
+		{
+			name: "fetch nil request",
+			call: func() error { _, err := cli.FetchAccountIdentification(nil); return err },
+			err:  accountclient.ErrNoRequest,
+		},
+		{
+			name: "delete nil request",
+			call: func() error { _, err := cli.DeleteAccountIdentification(nil); return err },
+			err:  accountclient.ErrNoRequest,
+		},
+		{
+			name: "list nil request",
+			call: func() error { _, err := cli.ListAccountIdentifications(nil); return err },
+			err:  accountclient.ErrNoRequest,
+		},
+		{
+			name: "list negative page number",
+			call: func() error {
+				_, err := cli.ListAccountIdentifications(&types.ListAccountIdentificationsRequest{PageNumber: -1})
+				return err
+			},
+			err: accountclient.ErrInvalidRequest,
+		},
+		{
+			name: "create error do request",
+			call: func() error {
+				_, err := cli.CreateAccountIdentification(&types.CreateAccountIdentificationRequest{})
+				return err
+			},
+			err: accountclient.ErrDoRequest,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.call(); !errors.Is(err, tc.err) {
+				t.Fatalf("unexpected error %v ; expected %v", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestClientIdentifications(t *testing.T) {
+	accountID := strfmt.UUID("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+	organisationID := "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c"
+	identificationID := strfmt.UUID("7826c3cb-d6fd-41d0-b187-dc23ba928772")
+	stub := &identificationsStub{prefix: "/v1/organisation/accounts/" + accountID.String() + "/identifications"}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	cli, err := accountclient.NewClientWithOptions(server.URL, accountclient.WithOrganisationID(organisationID))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	req := &types.CreateAccountIdentificationRequest{
+		AccountID: accountID,
+		Data: &types.AccountIdentification{
+			ID:         identificationID,
+			Type:       "account_identifications",
+			Attributes: &types.AccountIdentificationAttributes{SecondaryIdentification: "KYC-0001"},
+		},
+	}
+	created, err := cli.CreateAccountIdentification(req)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if created.Data.OrganisationID.String() != organisationID || req.Data.OrganisationID != "" {
+		t.Fatalf("default organisation id not set on a copy: %s", created.Data.OrganisationID)
+	}
+
+	// the secondary identification must be unique
+	req.Data.ID = "00000000-0000-0000-0000-000000000001"
+	if _, err := cli.CreateAccountIdentification(req); !errors.Is(err, accountclient.ErrAPIFailure) {
+		t.Fatalf("unexpected error %v ; expected %v", err, accountclient.ErrAPIFailure)
+	}
+
+	fetched, err := cli.FetchAccountIdentification(&types.FetchAccountIdentificationRequest{AccountID: accountID, IdentificationID: identificationID})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if fetched.Data.Attributes.SecondaryIdentification != "KYC-0001" {
+		t.Fatalf("wrong identification %+v", fetched.Data.Attributes)
+	}
+
+	list, err := cli.ListAccountIdentifications(&types.ListAccountIdentificationsRequest{AccountID: accountID, SecondaryIdentifications: []string{"KYC-0001"}})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(list.Data) != 1 || list.Data[0].ID != identificationID {
+		t.Fatalf("wrong identifications %v", list.Data)
+	}
+
+	_, err = cli.DeleteAccountIdentification(&types.DeleteAccountIdentificationRequest{AccountID: accountID, IdentificationID: identificationID, Version: 1})
+	if !errors.Is(err, accountclient.ErrVersionConflict) {
+		t.Fatalf("unexpected error %v ; expected %v", err, accountclient.ErrVersionConflict)
+	}
+	_, err = cli.DeleteAccountIdentification(&types.DeleteAccountIdentificationRequest{AccountID: accountID, IdentificationID: identificationID})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	_, err = cli.FetchAccountIdentification(&types.FetchAccountIdentificationRequest{AccountID: accountID, IdentificationID: identificationID})
+	var accErr *accountclient.AccountError
+	if !errors.As(err, &accErr) || accErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("unexpected error %v ; expected status 404", err)
+	}
+}
+
+func TestClientIdentificationsReplayedCreate(t *testing.T) {
+	accountID := strfmt.UUID("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+	organisationID := strfmt.UUID("eb0bd6f5-c3f5-44b2-b677-acd23cdde73c")
+	identificationID := strfmt.UUID("7826c3cb-d6fd-41d0-b187-dc23ba928772")
+	tt := []struct {
+		name  string
+		lands bool
+		err   error
+	}{
+		{name: "identification created by the first attempt", lands: true},
+		{name: "other identification", err: accountclient.ErrAPIFailure},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			stub := &identificationsStub{prefix: "/v1/organisation/accounts/" + accountID.String() + "/identifications"}
+			if !tc.lands {
+				// another identification already has the secondary identification
+				stub.identifications = append(stub.identifications, &types.AccountIdentification{
+					ID:         "00000000-0000-0000-0000-000000000001",
+					Attributes: &types.AccountIdentificationAttributes{SecondaryIdentification: "KYC-0001"},
+				})
+			}
+			var posts int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// the first attempt reaches the API (when it lands) but its response is lost
+				if r.Method == http.MethodPost && atomic.AddInt32(&posts, 1) == 1 {
+					if tc.lands {
+						stub.ServeHTTP(httptest.NewRecorder(), r)
+					}
+					w.WriteHeader(http.StatusBadGateway)
+					return
+				}
+				stub.ServeHTTP(w, r)
+			}))
+			defer server.Close()
+
+			cli, err := accountclient.NewClientWithOptions(server.URL,
+				accountclient.WithOrganisationID(organisationID.String()),
+				accountclient.WithRetryPolicy(&accountclient.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}),
+			)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			res, err := cli.CreateAccountIdentification(&types.CreateAccountIdentificationRequest{
+				AccountID: accountID,
+				Data: &types.AccountIdentification{
+					ID:         identificationID,
+					Attributes: &types.AccountIdentificationAttributes{SecondaryIdentification: "KYC-0001"},
+				},
+			})
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("unexpected error %v ; expected %v", err, tc.err)
+			}
+			if tc.err == nil && (res == nil || res.Data == nil || res.Data.ID != identificationID) {
+				t.Fatalf("wrong create response %+v", res)
+			}
+			if posts != 2 {
+				t.Fatalf("wrong number of attempts: want 2 got %d", posts)
+			}
+		})
+	}
+}
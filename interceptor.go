@@ -0,0 +1,77 @@
+package accountclient
+
+import (
+	"context"
+	"net/http"
+)
+
+// Operation names of the Invocations, the name of the method sending the request
+const (
+	OperationCreateAccount                  = "CreateAccount"
+	OperationFetchAccount                   = "FetchAccount"
+	OperationDeleteAccount                  = "DeleteAccount"
+	OperationAmendAccount                   = "AmendAccount"
+	OperationListAccounts                   = "ListAccounts"
+	OperationFetchAccountEvents             = "FetchAccountEvents"
+	OperationCreateAccountIdentification    = "CreateAccountIdentification"
+	OperationFetchAccountIdentification     = "FetchAccountIdentification"
+	OperationDeleteAccountIdentification    = "DeleteAccountIdentification"
+	OperationListAccountIdentifications     = "ListAccountIdentifications"
+	OperationCreateAmendment                = "AccountAmendments.Create"
+	OperationFetchAmendment                 = "AccountAmendments.Fetch"
+	OperationCreateAmendmentSubmission      = "AccountAmendments.CreateSubmission"
+	OperationFetchAmendmentSubmission       = "AccountAmendments.FetchSubmission"
+	OperationCreateAccountRequest           = "AccountRequests.Create"
+	OperationFetchAccountRequest            = "AccountRequests.Fetch"
+	OperationListAccountRequests            = "AccountRequests.List"
+	OperationCreateAccountRequestSubmission = "AccountRequests.CreateSubmission"
+	OperationFetchAccountRequestSubmission  = "AccountRequests.FetchSubmission"
+)
+
+/*
+Invocation is an attempt of a Client operation, as seen by the interceptors.
+Request is the typed request of the operation (e.g. *types.CreateAccountRequest) and HTTPRequest the request sent for it,
+with its headers, authorization included. Attempt counts the attempts of the operation from 1 when it is retried.
+*/
+type Invocation struct {
+	Operation   string
+	Request     interface{}
+	HTTPRequest *http.Request
+	Attempt     int
+
+	// response is the response checked and decoded by the client, and failure the error of its check
+	response *http.Response
+	failure  *AccountError
+}
+
+// Handler sends the HTTP request of an Invocation
+type Handler func(inv *Invocation) (*http.Response, error)
+
+/*
+Interceptor runs around every attempt of every Client operation.
+It calls next to send the request, and sees the response (with its body already read) and the resulting error:
+an *AccountError for a failed status code or response, as returned by the operation.
+It may change the HTTP request before calling next (the request is signed after the interceptors), or not call it at all:
+a response it returns is then checked and decoded as if it came from the API, and an error which is not an *AccountError is
+reported as ErrDoRequest. The error of the last response returned by next cannot be dropped: the operation still fails with it.
+*/
+type Interceptor func(inv *Invocation, next Handler) (*http.Response, error)
+
+/*
+OperationHook is called once per Client operation, before its first attempt, with the operation name and its typed request.
+The attempts (and their interceptors) run with the context it returns, and end, when not nil, is called after the last attempt
+with the decoded response of the operation (e.g. *types.FetchAccountResponse, nil when it has none or failed) and its error.
+*/
+type OperationHook func(ctx context.Context, operation string, request interface{}) (_ context.Context, end func(response interface{}, err error))
+
+// intercept sends inv through the client interceptors, the first one being the outermost, down to send
+func (c *Client) intercept(inv *Invocation, send Handler) (*http.Response, error) {
+	h := send
+	for i := len(c.interceptors) - 1; i >= 0; i-- {
+		interceptor, next := c.interceptors[i], h
+		h = func(inv *Invocation) (*http.Response, error) {
+			return interceptor(inv, next)
+		}
+	}
+	return h(inv)
+}
@@ -0,0 +1,273 @@
+package accountclient_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/localhost418/accountclient"
+	"github.com/localhost418/accountclient/accountapitest"
+	"github.com/localhost418/accountclient/generated/models"
+	"github.com/localhost418/accountclient/types"
+)
+
+func TestClientInterceptors(t *testing.T) {
+	srv := accountapitest.NewServer()
+	defer srv.Close()
+
+	var calls []string
+	var errs []error
+	var requests []interface{}
+	record := func(name string) accountclient.Interceptor {
+		return func(inv *accountclient.Invocation, next accountclient.Handler) (*http.Response, error) {
+			calls = append(calls, name+">"+inv.Operation)
+			res, err := next(inv)
+			status := 0
+			if res != nil {
+				status = res.StatusCode
+			}
+			calls = append(calls, "<"+name+" "+http.StatusText(status))
+			if name == "outer" {
+				errs = append(errs, err)
+				requests = append(requests, inv.Request)
+			}
+			return res, err
+		}
+	}
+	cli, err := accountclient.NewClientWithOptions(srv.URL,
+		accountclient.WithOrganisationID("eb0bd6f5-c3f5-44b2-b677-acd23cdde73c"),
+		accountclient.WithInterceptors(record("outer")),
+		accountclient.WithInterceptors(record("inner")),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	accountID := strfmt.UUID("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+	country := "GB"
+	createReq := &types.CreateAccountRequest{Data: &models.Account{ID: &accountID, Attributes: &models.AccountAttributes{Country: &country}}}
+	if _, err := cli.CreateAccount(createReq); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	fetchReq := &types.FetchAccountRequest{AccountID: "00000000-0000-0000-0000-000000000000"}
+	_, err = cli.FetchAccount(fetchReq)
+	if !errors.Is(err, accountclient.ErrAPIFailure) {
+		t.Fatalf("unexpected error %v ; expected %v", err, accountclient.ErrAPIFailure)
+	}
+
+	want := []string{
+		"outer>CreateAccount", "inner>CreateAccount", "<inner Created", "<outer Created",
+		"outer>FetchAccount", "inner>FetchAccount", "<inner Not Found", "<outer Not Found",
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("wrong calls: want %v got %v", want, calls)
+	}
+	if errs[0] != nil || !errors.Is(errs[1], accountclient.ErrAPIFailure) || errs[1] != err {
+		t.Fatalf("wrong errors seen by the interceptors %v", errs)
+	}
+	// the create request is the copy with the client organisation
+	created, ok := requests[0].(*types.CreateAccountRequest)
+	if !ok || created.Data.OrganisationID == nil || requests[1] != fetchReq {
+		t.Fatalf("wrong requests seen by the interceptors %v", requests)
+	}
+}
+
+func TestClientInterceptorHeaders(t *testing.T) {
+	var got string
+	srv := accountapitest.NewServer()
+	defer srv.Close()
+	cli, _ := accountclient.NewClientWithOptions(srv.URL,
+		accountclient.WithTransport(roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			got = r.Header.Get("X-Request-Id")
+			return http.DefaultTransport.RoundTrip(r)
+		})),
+		accountclient.WithInterceptors(func(inv *accountclient.Invocation, next accountclient.Handler) (*http.Response, error) {
+			inv.HTTPRequest.Header.Set("X-Request-Id", inv.Operation)
+			return next(inv)
+		}),
+	)
+
+	it := cli.ListAccountsIterator(context.Background(), &types.ListAccountsRequest{}, nil)
+	for it.Next() {
+	}
+	if it.Err() != nil || got != accountclient.OperationListAccounts {
+		t.Fatalf("wrong request id %q (error %v)", got, it.Err())
+	}
+}
+
+func TestClientInterceptorFaults(t *testing.T) {
+	srv := accountapitest.NewServer()
+	defer srv.Close()
+
+	tt := []struct {
+		name     string
+		fault    func(inv *accountclient.Invocation, next accountclient.Handler) (*http.Response, error)
+		attempts []int
+		err      error
+		status   int
+	}{
+		{
+			name: "unavailable then sent",
+			fault: func(inv *accountclient.Invocation, next accountclient.Handler) (*http.Response, error) {
+				if inv.Attempt == 1 {
+					return &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(`{"error_message":"injected"}`))}, nil
+				}
+				return next(inv)
+			},
+			attempts: []int{1, 2},
+		},
+		{
+			name: "unavailable",
+			fault: func(inv *accountclient.Invocation, next accountclient.Handler) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}}, nil
+			},
+			attempts: []int{1, 2},
+			err:      accountclient.ErrAPIFailure,
+			status:   http.StatusServiceUnavailable,
+		},
+		{
+			name: "transport error",
+			fault: func(inv *accountclient.Invocation, next accountclient.Handler) (*http.Response, error) {
+				return nil, errors.New("connection reset")
+			},
+			attempts: []int{1, 2},
+			err:      accountclient.ErrDoRequest,
+		},
+		{
+			name: "no response",
+			fault: func(inv *accountclient.Invocation, next accountclient.Handler) (*http.Response, error) {
+				return nil, nil
+			},
+			attempts: []int{1, 2},
+			err:      accountclient.ErrDoRequest,
+		},
+		{
+			name: "swallowed error",
+			fault: func(inv *accountclient.Invocation, next accountclient.Handler) (*http.Response, error) {
+				inv.HTTPRequest.URL.RawQuery = "page%5Bsize%5D=-1"
+				w, _ := next(inv)
+				return w, nil
+			},
+			attempts: []int{1},
+			err:      accountclient.ErrAPIFailure,
+			status:   http.StatusBadRequest,
+		},
+		{
+			name: "sent again",
+			fault: func(inv *accountclient.Invocation, next accountclient.Handler) (*http.Response, error) {
+				query := inv.HTTPRequest.URL.RawQuery
+				inv.HTTPRequest.URL.RawQuery = "page%5Bsize%5D=-1"
+				if _, err := next(inv); err == nil {
+					return nil, errors.New("expected a bad request")
+				}
+				inv.HTTPRequest.URL.RawQuery = query
+				return next(inv)
+			},
+			attempts: []int{1},
+		},
+		{
+			name: "rejected",
+			fault: func(inv *accountclient.Invocation, next accountclient.Handler) (*http.Response, error) {
+				return nil, accountclient.NewAccountError(accountclient.ErrInvalidRequest, inv.HTTPRequest.Method, "rejected", 0, nil)
+			},
+			attempts: []int{1},
+			err:      accountclient.ErrInvalidRequest,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var attempts []int
+			cli, _ := accountclient.NewClientWithOptions(srv.URL,
+				accountclient.WithRetryPolicy(&accountclient.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}),
+				accountclient.WithInterceptors(func(inv *accountclient.Invocation, next accountclient.Handler) (*http.Response, error) {
+					attempts = append(attempts, inv.Attempt)
+					return next(inv)
+				}, tc.fault),
+			)
+
+			res, err := cli.ListAccounts(&types.ListAccountsRequest{})
+			var accErr *accountclient.AccountError
+			switch {
+			case tc.err == nil && (err != nil || res == nil):
+				t.Fatalf("unexpected error %v", err)
+			case tc.err != nil && (!errors.As(err, &accErr) || !errors.Is(err, tc.err) || accErr.StatusCode != tc.status):
+				t.Fatalf("unexpected error %v ; expected %v", err, tc.err)
+			}
+			if !reflect.DeepEqual(attempts, tc.attempts) {
+				t.Fatalf("wrong attempts: want %v got %v", tc.attempts, attempts)
+			}
+		})
+	}
+}
+
+func TestClientOperationHooks(t *testing.T) {
+	srv := accountapitest.NewServer()
+	defer srv.Close()
+
+	type ctxKey struct{}
+	var calls []string
+	var responses []interface{}
+	var errs []error
+	hook := func(name string) accountclient.OperationHook {
+		return func(ctx context.Context, operation string, request interface{}) (context.Context, func(interface{}, error)) {
+			calls = append(calls, name+">"+operation)
+			return context.WithValue(ctx, ctxKey{}, name), func(response interface{}, err error) {
+				calls = append(calls, "<"+name)
+				if name == "outer" {
+					responses = append(responses, response)
+					errs = append(errs, err)
+				}
+			}
+		}
+	}
+	fails := 1
+	cli, err := accountclient.NewClientWithOptions(srv.URL,
+		accountclient.WithRetryPolicy(&accountclient.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}),
+		accountclient.WithOrganisationID("eb0bd6f5-c3f5-44b2-b677-acd23cdde73c"),
+		accountclient.WithOperationHooks(hook("outer"), hook("inner")),
+		accountclient.WithInterceptors(func(inv *accountclient.Invocation, next accountclient.Handler) (*http.Response, error) {
+			calls = append(calls, fmt.Sprintf("attempt %d of %v", inv.Attempt, inv.HTTPRequest.Context().Value(ctxKey{})))
+			if fails > 0 {
+				fails--
+				return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: io.NopCloser(strings.NewReader(`{}`))}, nil
+			}
+			return next(inv)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, err := accountclient.NewClientWithOptions(srv.URL, accountclient.WithOperationHooks(nil)); err == nil {
+		t.Fatalf("expected a nil operation hook error")
+	}
+
+	accountID := strfmt.UUID("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+	country := "GB"
+	created, err := cli.CreateAccount(&types.CreateAccountRequest{Data: &models.Account{ID: &accountID, Attributes: &models.AccountAttributes{Country: &country}}})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	_, err = cli.DeleteAccount(&types.DeleteAccountRequest{AccountID: accountID, Version: 1})
+
+	want := []string{
+		"outer>CreateAccount", "inner>CreateAccount", "attempt 1 of inner", "attempt 2 of inner", "<inner", "<outer",
+		"outer>DeleteAccount", "inner>DeleteAccount", "attempt 1 of inner", "<inner", "<outer",
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("wrong calls: want %v got %v", want, calls)
+	}
+	if responses[0] != created || errs[0] != nil {
+		t.Fatalf("wrong end of the create: %v %v", responses[0], errs[0])
+	}
+	if responses[1] != nil || !errors.Is(errs[1], accountclient.ErrVersionConflict) || !errors.Is(err, accountclient.ErrVersionConflict) {
+		t.Fatalf("unexpected error %v ; expected %v", errs[1], accountclient.ErrVersionConflict)
+	}
+}
@@ -0,0 +1,192 @@
+package accountclient
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/localhost418/accountclient/generated/models"
+	"github.com/localhost418/accountclient/types"
+)
+
+// IteratorOptions configures an AccountIterator
+type IteratorOptions struct {
+	// Prefetch fetches the next page in background while the current one is consumed
+	Prefetch bool
+
+	// MaxItems caps the total number of accounts returned by the iterator (no cap when zero)
+	MaxItems int
+}
+
+// AccountIterator walks an account listing page by page by following the links.next URL returned by the API.
+//
+//	it := cli.ListAccountsIterator(ctx, &types.ListAccountsRequest{PageSize: 100}, nil)
+//	for it.Next() {
+//		account := it.Account()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+type AccountIterator struct {
+	ctx    context.Context
+	client *Client
+	req    *types.ListAccountsRequest
+	opts   IteratorOptions
+
+	started bool
+	next    string
+	page    []*models.Account
+	index   int
+	count   int
+	current *models.Account
+	pending chan pageResult
+	err     error
+
+	// visited holds the URLs of the pages fetched so far, see linkKey
+	visited map[string]bool
+}
+
+// pageResult is a page fetched by the iterator (possibly in background)
+type pageResult struct {
+	res *types.ListAccountsResponse
+	err error
+}
+
+// ListAccountsIterator returns an iterator over every account matching the request filters (opts may be nil)
+func (c *Client) ListAccountsIterator(ctx context.Context, req *types.ListAccountsRequest, opts *IteratorOptions) *AccountIterator {
+	it := &AccountIterator{
+		ctx:    ctx,
+		client: c,
+		req:    req,
+	}
+	if opts != nil {
+		it.opts = *opts
+	}
+	return it
+}
+
+// Next advances the iterator to the next account, it returns false when the listing is exhausted or on error
+func (it *AccountIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if it.opts.MaxItems > 0 && it.count >= it.opts.MaxItems {
+		it.current = nil
+		return false
+	}
+	for it.index >= len(it.page) {
+		if !it.fetchPage() {
+			it.current = nil
+			return false
+		}
+	}
+	it.current = it.page[it.index]
+	it.index++
+	it.count++
+	return true
+}
+
+// Account returns the current account (nil before the first call to Next or once the iterator is done)
+func (it *AccountIterator) Account() *models.Account {
+	return it.current
+}
+
+// Err returns the error which stopped the iteration if any
+func (it *AccountIterator) Err() error {
+	return it.err
+}
+
+// fetchPage loads the next page, it returns false when there is no more page to load or on error
+func (it *AccountIterator) fetchPage() bool {
+	if it.started && it.next == "" {
+		return false
+	}
+
+	started, fetched := it.started, it.next
+	var p pageResult
+	if it.pending != nil {
+		p = <-it.pending
+		it.pending = nil
+	} else {
+		p = it.load(it.started, it.next)
+	}
+	it.started = true
+	if p.err != nil {
+		it.err = p.err
+		return false
+	}
+
+	it.page = p.res.Data
+	it.index = 0
+	it.next = ""
+	// a link to a page already fetched is never followed, a cycle of links would be followed forever
+	if !started {
+		fetched = buildURL(it.client.url, []string{it.client.accountsPath()}) + "?" + it.client.listQuery(it.req).Encode()
+	}
+	it.visit(fetched)
+	if p.res.Links != nil && p.res.Links.Self != nil {
+		it.visit(*p.res.Links.Self)
+	}
+	if p.res.Links != nil && p.res.Links.Next != nil && !it.visited[it.linkKey(*p.res.Links.Next)] {
+		it.next = *p.res.Links.Next
+	}
+	// an empty page ends the listing whatever the links say, to never loop on a misbehaving server
+	if len(it.page) == 0 {
+		it.next = ""
+		return false
+	}
+
+	if it.opts.Prefetch && it.next != "" && (it.opts.MaxItems == 0 || it.count+len(it.page) < it.opts.MaxItems) {
+		// buffered so the goroutine never blocks if the iterator is abandoned
+		it.pending = make(chan pageResult, 1)
+		go func(pending chan<- pageResult, next string) {
+			pending <- it.load(true, next)
+		}(it.pending, it.next)
+	}
+	return true
+}
+
+// visit records the page of link as fetched
+func (it *AccountIterator) visit(link string) {
+	if it.visited == nil {
+		it.visited = map[string]bool{}
+	}
+	it.visited[it.linkKey(link)] = true
+}
+
+// linkKey returns the URL of the page of link as resolved by the client, with its query parameters sorted
+func (it *AccountIterator) linkKey(link string) string {
+	resolved, err := it.client.resolveLink(link)
+	if err != nil {
+		return link
+	}
+	u, err := url.Parse(resolved)
+	if err != nil {
+		return resolved
+	}
+	u.RawQuery = u.Query().Encode()
+	return u.String()
+}
+
+// load fetches the first page from the request or a following page from its link
+func (it *AccountIterator) load(started bool, link string) pageResult {
+	if !started {
+		res, err := it.client.ListAccountsWithContext(it.ctx, it.req)
+		return pageResult{res: res, err: err}
+	}
+
+	res := &types.ListAccountsResponse{}
+	err := it.client.send(it.ctx, &apiCall{
+		operation:  OperationListAccounts,
+		request:    it.req,
+		method:     http.MethodGet,
+		endpoint:   it.client.accountsPath(),
+		link:       link,
+		status:     http.StatusOK,
+		idempotent: true,
+		response:   res,
+	})
+	if err != nil {
+		return pageResult{err: err}
+	}
+	return pageResult{res: res}
+}
@@ -0,0 +1,222 @@
+package accountclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/localhost418/accountclient"
+	"github.com/localhost418/accountclient/generated/models"
+	"github.com/localhost418/accountclient/types"
+)
+
+// pagedHandler serves count accounts by pages of size, failing with status failStatus when page failPage is requested
+func pagedHandler(t *testing.T, count, size, failPage, failStatus int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		page, _ := strconv.Atoi(r.URL.Query().Get("page[number]"))
+		if page == failPage {
+			w.WriteHeader(failStatus)
+			return
+		}
+
+		res := &types.ListAccountsResponse{Data: []*models.Account{}}
+		for i := page * size; i < count && i < (page+1)*size; i++ {
+			id := strfmt.UUID(fmt.Sprintf("ad27e265-9605-4b4b-a0e5-%012d", i))
+			res.Data = append(res.Data, &models.Account{ID: &id, Type: "accounts"})
+		}
+		self := r.URL.String()
+		res.Links = &types.AccountCreationResponseLinks{Self: &self}
+		if (page+1)*size < count {
+			next := fmt.Sprintf("/v1/organisation/accounts?page%%5Bnumber%%5D=%d&page%%5Bsize%%5D=%d", page+1, size)
+			res.Links.Next = &next
+		}
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(res); err != nil {
+			t.Errorf("cannot encode page: %s", err)
+		}
+	}
+}
+
+func TestAccountIterator(t *testing.T) {
+	tt := []struct {
+		name     string
+		opts     *accountclient.IteratorOptions
+		failPage int
+		count    int
+		err      error
+	}{
+		{
+			name:     "every account",
+			failPage: -1,
+			count:    5,
+		},
+		{
+			name:     "max items",
+			opts:     &accountclient.IteratorOptions{MaxItems: 3},
+			failPage: -1,
+			count:    3,
+		},
+		{
+			name:     "prefetch",
+			opts:     &accountclient.IteratorOptions{Prefetch: true},
+			failPage: -1,
+			count:    5,
+		},
+		{
+			name:     "prefetch and max items",
+			opts:     &accountclient.IteratorOptions{Prefetch: true, MaxItems: 4},
+			failPage: -1,
+			count:    4,
+		},
+		{
+			name:     "error on second page",
+			failPage: 1,
+			count:    2,
+			err:      accountclient.ErrAPIFailure,
+		},
+		{
+			name:     "error on second page with prefetch",
+			opts:     &accountclient.IteratorOptions{Prefetch: true},
+			failPage: 1,
+			count:    2,
+			err:      accountclient.ErrAPIFailure,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(pagedHandler(t, 5, 2, tc.failPage, http.StatusInternalServerError))
+			defer srv.Close()
+
+			serverURL, err := url.Parse(srv.URL)
+			if err != nil {
+				t.Fatalf("cannot build server url: %s", err)
+			}
+
+			cli := accountclient.NewClient(&http.Client{Timeout: time.Second}, *serverURL)
+			it := cli.ListAccountsIterator(context.Background(), &types.ListAccountsRequest{PageSize: 2}, tc.opts)
+
+			count := 0
+			for it.Next() {
+				want := strfmt.UUID(fmt.Sprintf("ad27e265-9605-4b4b-a0e5-%012d", count))
+				if got := it.Account(); got == nil || *got.ID != want {
+					t.Fatalf("wrong account %d: want %s got %v", count, want, got)
+				}
+				count++
+			}
+			if count != tc.count {
+				t.Fatalf("wrong number of accounts: want %d got %d", tc.count, count)
+			}
+			if it.Account() != nil {
+				t.Fatal("unexpected account once the iterator is done")
+			}
+
+			err = it.Err()
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("unexpected error %v ; expected %v", err, tc.err)
+			}
+			if it.Next() {
+				t.Fatal("unexpected Next once the iterator is done")
+			}
+		})
+	}
+}
+
+// linkedHandler serves pages of a single account and no self link, page returns the account number and next link of a request
+func linkedHandler(t *testing.T, page func(r *http.Request) (string, string)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		number, next := page(r)
+		id := strfmt.UUID("ad27e265-9605-4b4b-a0e5-00000000000" + number)
+		res := &types.ListAccountsResponse{
+			Data:  []*models.Account{{ID: &id, Type: "accounts"}},
+			Links: &types.AccountCreationResponseLinks{Next: &next},
+		}
+		if err := json.NewEncoder(w).Encode(res); err != nil {
+			t.Errorf("cannot encode page: %s", err)
+		}
+	}
+}
+
+func TestAccountIteratorLinks(t *testing.T) {
+	tt := []struct {
+		name  string
+		base  string
+		serve func(t *testing.T) http.Handler
+		count int
+	}{
+		{
+			name: "gateway path prefix",
+			base: "/gateway",
+			serve: func(t *testing.T) http.Handler {
+				return http.StripPrefix("/gateway", pagedHandler(t, 5, 2, -1, 0))
+			},
+			count: 5,
+		},
+		{
+			name: "self linking page",
+			serve: func(t *testing.T) http.Handler {
+				return linkedHandler(t, func(r *http.Request) (string, string) {
+					return "0", r.URL.String()
+				})
+			},
+			count: 1,
+		},
+		{
+			name: "next link to the first page",
+			serve: func(t *testing.T) http.Handler {
+				return linkedHandler(t, func(r *http.Request) (string, string) {
+					return "0", "/v1/organisation/accounts?page%5Bsize%5D=2"
+				})
+			},
+			count: 1,
+		},
+		{
+			name: "cycle of links",
+			serve: func(t *testing.T) http.Handler {
+				return linkedHandler(t, func(r *http.Request) (string, string) {
+					// the third page links back to the second one, with its parameters in another order
+					switch r.URL.Query().Get("page[number]") {
+					case "1":
+						return "1", "/v1/organisation/accounts?page%5Bnumber%5D=2&page%5Bsize%5D=2"
+					case "2":
+						return "2", "/v1/organisation/accounts?page%5Bsize%5D=2&page%5Bnumber%5D=1"
+					}
+					return "0", "/v1/organisation/accounts?page%5Bnumber%5D=1&page%5Bsize%5D=2"
+				})
+			},
+			count: 3,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(tc.serve(t))
+			defer srv.Close()
+
+			cli, err := accountclient.NewClientWithOptions(srv.URL + tc.base)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			it := cli.ListAccountsIterator(context.Background(), &types.ListAccountsRequest{PageSize: 2}, nil)
+			count := 0
+			for it.Next() && count < 10 {
+				count++
+			}
+			if err := it.Err(); err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if count != tc.count {
+				t.Fatalf("wrong number of accounts: want %d got %d", tc.count, count)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package accountclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/localhost418/accountclient/auth"
+	"github.com/localhost418/accountclient/countryrules"
+	"github.com/localhost418/accountclient/signing"
+)
+
+const (
+	// defaultTimeout is the timeout of the *http.Client created by NewClientWithOptions
+	defaultTimeout = 30 * time.Second
+
+	// oauth2TokenPath is the path of the token endpoint, under the API version prefix
+	oauth2TokenPath = "oauth2/token"
+)
+
+// Option configures a Client created by NewClientWithOptions
+type Option func(*Client) error
+
+// NewClientWithOptions creates a new Client for the API at baseURL (scheme, host and optional path prefix) configured by opts
+func NewClientWithOptions(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base url %q: %w", baseURL, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid base url %q: scheme and host are required", baseURL)
+	}
+
+	c := NewClient(&http.Client{Timeout: defaultTimeout}, *u)
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+
+	// WithClientCredentials token endpoint depends on the final url, api version and http client
+	if cc, ok := c.tokens.(*auth.ClientCredentials); ok && cc.TokenURL == "" {
+		cc.TokenURL = buildURL(c.url, []string{c.apiPath, oauth2TokenPath})
+		cc.HTTPClient = c.client
+	}
+	return c, nil
+}
+
+// WithHTTPClient uses a copy of client to send the requests (its transport and timeout are kept)
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) error {
+		if client == nil {
+			return errors.New("nil http client")
+		}
+		cp := *client
+		c.client = &cp
+		return nil
+	}
+}
+
+// WithTransport sends the requests through rt
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) error {
+		if rt == nil {
+			return errors.New("nil transport")
+		}
+		c.client.Transport = rt
+		return nil
+	}
+}
+
+// WithTimeout sets the timeout of each HTTP request (zero means no timeout)
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) error {
+		if timeout < 0 {
+			return fmt.Errorf("negative timeout %s", timeout)
+		}
+		c.client.Timeout = timeout
+		return nil
+	}
+}
+
+// WithAPIVersion replaces the "v1" API version prefix of the resource paths, e.g. "v2" or "gateway/v1"
+func WithAPIVersion(prefix string) Option {
+	return func(c *Client) error {
+		prefix = strings.Trim(prefix, "/")
+		if prefix == "" {
+			return errors.New("empty api version prefix")
+		}
+		c.apiPath = prefix
+		return nil
+	}
+}
+
+// WithHeader adds a header sent with every request (the Accept header cannot be overridden)
+func WithHeader(key, value string) Option {
+	return func(c *Client) error {
+		if c.headers == nil {
+			c.headers = http.Header{}
+		}
+		c.headers.Add(key, value)
+		return nil
+	}
+}
+
+// WithUserAgent sets the User-Agent header of every request
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) error {
+		c.userAgent = userAgent
+		return nil
+	}
+}
+
+// WithOrganisationID sets the organisation used when a create request has none and when a list request has no organisation filter
+func WithOrganisationID(organisationID string) Option {
+	return func(c *Client) error {
+		if !strfmt.IsUUID(organisationID) {
+			return fmt.Errorf("invalid organisation id %q", organisationID)
+		}
+		c.organisationID = strfmt.UUID(organisationID)
+		return nil
+	}
+}
+
+// WithRetryPolicy sets the policy used to retry transient failures
+func WithRetryPolicy(policy *RetryPolicy) Option {
+	return func(c *Client) error {
+		c.retry = policy
+		return nil
+	}
+}
+
+// WithTokenSource sends a bearer token from ts in the Authorization header of every request
+func WithTokenSource(ts auth.TokenSource) Option {
+	return func(c *Client) error {
+		if ts == nil {
+			return errors.New("nil token source")
+		}
+		c.tokens = ts
+		return nil
+	}
+}
+
+// WithClientCredentials authenticates every request with a token exchanged for clientID and clientSecret on the API /oauth2/token endpoint
+func WithClientCredentials(clientID, clientSecret string) Option {
+	return func(c *Client) error {
+		if clientID == "" || clientSecret == "" {
+			return errors.New("empty client credentials")
+		}
+		c.tokens = &auth.ClientCredentials{ClientID: clientID, ClientSecret: clientSecret}
+		return nil
+	}
+}
+
+// WithSigner signs every request with s (Digest and Signature headers)
+func WithSigner(s *signing.Signer) Option {
+	return func(c *Client) error {
+		if s == nil {
+			return errors.New("nil signer")
+		}
+		c.signer = s
+		return nil
+	}
+}
+
+// WithValidation validates create and amend requests with the generated model validators before sending them (ErrValidation on failure)
+func WithValidation() Option {
+	return func(c *Client) error {
+		c.validate = true
+		return nil
+	}
+}
+
+// WithCountryRules validates create requests against the country rules of the registry (countryrules.Default() if nil), it implies WithValidation
+func WithCountryRules(r *countryrules.Registry) Option {
+	return func(c *Client) error {
+		if r == nil {
+			r = countryrules.Default()
+		}
+		c.validate = true
+		c.countryRules = r
+		return nil
+	}
+}
+
+// WithInterceptors adds interceptors run around every attempt of every operation, the first one being the outermost
+func WithInterceptors(interceptors ...Interceptor) Option {
+	return func(c *Client) error {
+		for _, i := range interceptors {
+			if i == nil {
+				return errors.New("nil interceptor")
+			}
+		}
+		c.interceptors = append(c.interceptors, interceptors...)
+		return nil
+	}
+}
+
+// WithOperationHooks adds hooks run around every operation, once whatever its number of attempts, the first one being the outermost
+func WithOperationHooks(hooks ...OperationHook) Option {
+	return func(c *Client) error {
+		for _, h := range hooks {
+			if h == nil {
+				return errors.New("nil operation hook")
+			}
+		}
+		c.hooks = append(c.hooks, hooks...)
+		return nil
+	}
+}
@@ -0,0 +1,285 @@
+package accountclient_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/localhost418/accountclient"
+	"github.com/localhost418/accountclient/generated/models"
+	"github.com/localhost418/accountclient/signing"
+	"github.com/localhost418/accountclient/types"
+)
+
+// roundTripperFunc implements http.RoundTripper with a function
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestNewClientWithOptionsErrors(t *testing.T) {
+	tt := []struct {
+		name    string
+		baseURL string
+		opts    []accountclient.Option
+	}{
+		{
+			name:    "invalid url",
+			baseURL: "http://[::1",
+		},
+		{
+			name:    "relative url",
+			baseURL: "/v1",
+		},
+		{
+			name:    "empty api version",
+			baseURL: "http://localhost:8080",
+			opts:    []accountclient.Option{accountclient.WithAPIVersion("/")},
+		},
+		{
+			name:    "invalid organisation id",
+			baseURL: "http://localhost:8080",
+			opts:    []accountclient.Option{accountclient.WithOrganisationID("organisation")},
+		},
+		{
+			name:    "negative timeout",
+			baseURL: "http://localhost:8080",
+			opts:    []accountclient.Option{accountclient.WithTimeout(-time.Second)},
+		},
+		{
+			name:    "nil transport",
+			baseURL: "http://localhost:8080",
+			opts:    []accountclient.Option{accountclient.WithTransport(nil)},
+		},
+		{
+			name:    "nil http client",
+			baseURL: "http://localhost:8080",
+			opts:    []accountclient.Option{accountclient.WithHTTPClient(nil)},
+		},
+		{
+			name:    "nil interceptor",
+			baseURL: "http://localhost:8080",
+			opts:    []accountclient.Option{accountclient.WithInterceptors(nil)},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			cli, err := accountclient.NewClientWithOptions(tc.baseURL, tc.opts...)
+			if err == nil || cli != nil {
+				t.Fatalf("expected an error, got client %v", cli)
+			}
+		})
+	}
+}
+
+func TestNewClientWithOptions(t *testing.T) {
+	accountID := strfmt.UUID("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+	organisationID := "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c"
+
+	var received []*http.Request
+	var bodies []*types.CreateAccountRequest
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		received = append(received, r)
+		switch r.Method {
+		case http.MethodPost:
+			body := &types.CreateAccountRequest{}
+			if err := json.NewDecoder(r.Body).Decode(body); err != nil {
+				t.Errorf("cannot decode request body: %s", err)
+			}
+			bodies = append(bodies, body)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"data":{}}`))
+		case http.MethodGet:
+			if r.URL.Path == "/gateway/v2/organisation/accounts/slow" {
+				time.Sleep(200 * time.Millisecond)
+			}
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"data":[]}`))
+		}
+	})
+
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	var roundTrips int32
+	transport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&roundTrips, 1)
+		return http.DefaultTransport.RoundTrip(r)
+	})
+
+	cli, err := accountclient.NewClientWithOptions(srv.URL+"/gateway",
+		accountclient.WithAPIVersion("v2"),
+		accountclient.WithHeader("X-Request-Source", "tests"),
+		accountclient.WithHeader("Accept", "text/html"),
+		accountclient.WithUserAgent("accountclient-tests/1.0"),
+		accountclient.WithTransport(transport),
+		accountclient.WithTimeout(100*time.Millisecond),
+		accountclient.WithOrganisationID(organisationID),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// create without organisation gets the default one, the caller request is untouched
+	req := &types.CreateAccountRequest{Data: &models.Account{ID: &accountID}}
+	if _, err := cli.CreateAccount(req); err != nil {
+		t.Fatalf("unexpected create error: %s", err)
+	}
+	if req.Data.OrganisationID != nil {
+		t.Fatal("caller request modified")
+	}
+	if len(bodies) != 1 || bodies[0].Data.OrganisationID == nil || bodies[0].Data.OrganisationID.String() != organisationID {
+		t.Fatalf("default organisation not sent: %+v", bodies)
+	}
+
+	// list without organisation filter gets the default one
+	if _, err := cli.ListAccounts(&types.ListAccountsRequest{}); err != nil {
+		t.Fatalf("unexpected list error: %s", err)
+	}
+	// list with organisation filter keeps it
+	if _, err := cli.ListAccounts(&types.ListAccountsRequest{OrganisationIDs: []strfmt.UUID{accountID}}); err != nil {
+		t.Fatalf("unexpected list error: %s", err)
+	}
+
+	for _, r := range received {
+		if r.URL.Path != "/gateway/v2/organisation/accounts" {
+			t.Fatalf("wrong path %s", r.URL.Path)
+		}
+		if r.Header.Get("X-Request-Source") != "tests" || r.Header.Get("Accept") != "application/vnd.api+json" || r.UserAgent() != "accountclient-tests/1.0" {
+			t.Fatalf("wrong headers %v", r.Header)
+		}
+	}
+	if got := received[1].URL.Query().Get("filter[organisation_id]"); got != organisationID {
+		t.Fatalf("wrong default organisation filter %q", got)
+	}
+	if got := received[2].URL.Query().Get("filter[organisation_id]"); got != accountID.String() {
+		t.Fatalf("wrong organisation filter %q", got)
+	}
+	if roundTrips != 3 {
+		t.Fatalf("custom transport not used: %d round trips", roundTrips)
+	}
+
+	// timeout
+	_, err = cli.FetchAccount(&types.FetchAccountRequest{AccountID: "slow"})
+	if !errors.Is(err, accountclient.ErrDoRequest) {
+		t.Fatalf("unexpected error %v ; expected %v", err, accountclient.ErrDoRequest)
+	}
+}
+
+func TestClientAuthentication(t *testing.T) {
+	var tokenCalls int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		switch r.URL.Path {
+		case "/v1/oauth2/token":
+			n := atomic.AddInt32(&tokenCalls, 1)
+			if id, secret, ok := r.BasicAuth(); !ok || id != "client-id" || secret != "client-secret" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			fmt.Fprintf(w, `{"access_token":"token-%d","expires_in":3600,"token_type":"Bearer"}`, n)
+		case "/v1/organisation/accounts/ad27e265-9605-4b4b-a0e5-3003ea9cc4dc":
+			// the first token gets revoked
+			if r.Header.Get("Authorization") != "Bearer token-2" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"data":{}}`))
+		}
+	})
+
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	req := &types.FetchAccountRequest{AccountID: "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc"}
+
+	cli, err := accountclient.NewClientWithOptions(srv.URL, accountclient.WithClientCredentials("client-id", "client-secret"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	_, err = cli.FetchAccount(req)
+	var accErr *accountclient.AccountError
+	if !errors.As(err, &accErr) || accErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("unexpected error %v ; expected status %d", err, http.StatusUnauthorized)
+	}
+	if _, err := cli.FetchAccount(req); err != nil {
+		t.Fatalf("unexpected error with refreshed token: %s", err)
+	}
+	if _, err := cli.FetchAccount(req); err != nil {
+		t.Fatalf("unexpected error with cached token: %s", err)
+	}
+	if tokenCalls != 2 {
+		t.Fatalf("wrong number of token requests: want 2 got %d", tokenCalls)
+	}
+
+	cli, err = accountclient.NewClientWithOptions(srv.URL, accountclient.WithClientCredentials("client-id", "wrong-secret"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	_, err = cli.FetchAccount(req)
+	if !errors.Is(err, accountclient.ErrAuthentication) {
+		t.Fatalf("unexpected error %v ; expected %v", err, accountclient.ErrAuthentication)
+	}
+}
+
+func TestClientSigning(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("cannot generate key: %s", err)
+	}
+	signer, err := signing.NewSigner("75a8ba12-fff2-4a52-ad8a-e8b34c5ccec8", key)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	verifier := &signing.Verifier{Keys: map[string]crypto.PublicKey{signer.KeyID(): &key.PublicKey}}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		if err := verifier.Verify(r); err != nil {
+			t.Errorf("invalid signature for %s %s: %s", r.Method, r.URL, err)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.Method {
+		case http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Write([]byte(`{"data":{}}`))
+	})
+
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	cli, err := accountclient.NewClientWithOptions(srv.URL, accountclient.WithSigner(signer))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	accountID := strfmt.UUID("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+	if _, err := cli.CreateAccount(&types.CreateAccountRequest{Data: &models.Account{ID: &accountID}}); err != nil {
+		t.Fatalf("unexpected create error: %s", err)
+	}
+	if _, err := cli.FetchAccount(&types.FetchAccountRequest{AccountID: accountID}); err != nil {
+		t.Fatalf("unexpected fetch error: %s", err)
+	}
+	if _, err := cli.DeleteAccount(&types.DeleteAccountRequest{AccountID: accountID, Version: 0}); err != nil {
+		t.Fatalf("unexpected delete error: %s", err)
+	}
+}
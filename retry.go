@@ -0,0 +1,149 @@
+package accountclient
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+/*
+RetryPolicy configures how the Client retries transient failures: transport errors (connection reset, ...),
+429 Too Many Requests and 500, 502, 503, 504 responses.
+Only idempotent operations are retried unless RetryNonIdempotent is set: Fetch, List, Delete (versioned)
+and Create when the request carries its account ID (the API rejects a second POST of the same ID).
+A retried Create answered with 409, its first attempt having created the account, returns the existing account
+when it has the attributes of the request, as CreateAccountIdempotentWithContext does.
+*/
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts including the first one (no retry when <= 1)
+	MaxAttempts int
+
+	// BaseDelay is the delay before the first retry, doubled for each following retry
+	BaseDelay time.Duration
+
+	// MaxDelay caps the backoff delay between two attempts (no cap when zero),
+	// a Retry-After header asking for a longer delay stops the retries rather than retrying early
+	MaxDelay time.Duration
+
+	// Jitter is the fraction (between 0 and 1) of each delay which is randomized to spread the retries of concurrent clients
+	Jitter float64
+
+	// RetryNonIdempotent also retries the operations which are not safe to replay (Amend, Create without account ID)
+	RetryNonIdempotent bool
+
+	// OnAttempt, when set, is called after each attempt (for logging or metrics)
+	OnAttempt func(Attempt)
+}
+
+// Attempt describes an attempt of an operation, reported to RetryPolicy.OnAttempt
+type Attempt struct {
+	Method   string
+	Endpoint string
+
+	// Number of the attempt, starting at 1
+	Number int
+
+	// Err is the error of the attempt (nil on success)
+	Err error
+
+	// Retry tells whether the operation is retried, after Delay
+	Retry bool
+	Delay time.Duration
+}
+
+// DefaultRetryPolicy returns a policy making up to 3 attempts with an exponential backoff from 100ms to 2s and 20% jitter
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   100 * time.Millisecond,
+		MaxDelay:    2 * time.Second,
+		Jitter:      0.2,
+	}
+}
+
+// SetRetryPolicy sets the policy used to retry transient failures (nil disables retries, the default)
+func (c *Client) SetRetryPolicy(policy *RetryPolicy) {
+	c.retry = policy
+}
+
+// backoff tells whether call must be retried after attempt failed with accErr, and the delay to wait before retrying
+func (p *RetryPolicy) backoff(call *apiCall, attempt int, accErr *AccountError, retryAfter time.Duration) (time.Duration, bool) {
+	if p == nil {
+		return 0, false
+	}
+
+	var delay time.Duration
+	retry := accErr != nil && attempt < p.MaxAttempts && (call.idempotent || p.RetryNonIdempotent) && retryable(accErr)
+	if retry {
+		delay = math.MaxInt64
+		if shift := uint(attempt - 1); shift < 63 && p.BaseDelay <= math.MaxInt64>>shift {
+			delay = p.BaseDelay << shift
+		}
+		// otherwise the shift would overflow, MaxDelay caps it below
+		if p.Jitter > 0 {
+			delay -= time.Duration(p.Jitter * rand.Float64() * float64(delay))
+		}
+		if p.MaxDelay > 0 && delay > p.MaxDelay {
+			delay = p.MaxDelay
+		}
+		if retryAfter > delay {
+			delay = retryAfter
+		}
+		if p.MaxDelay > 0 && retryAfter > p.MaxDelay {
+			// the server would reject an earlier retry
+			retry, delay = false, 0
+		}
+	}
+
+	if p.OnAttempt != nil {
+		a := Attempt{
+			Method:   call.method,
+			Endpoint: call.endpoint,
+			Number:   attempt,
+			Retry:    retry,
+			Delay:    delay,
+		}
+		if accErr != nil {
+			a.Err = accErr
+		}
+		p.OnAttempt(a)
+	}
+	return delay, retry
+}
+
+// retryable tells whether accErr is a transient failure
+func retryable(accErr *AccountError) bool {
+	if errors.Is(accErr, ErrDoRequest) {
+		return true
+	}
+	if !errors.Is(accErr, ErrAPIFailure) {
+		return false
+	}
+	switch accErr.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter parses a Retry-After header value (delay in seconds or HTTP date), it returns 0 if the value is invalid
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if d := time.Until(date); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
@@ -0,0 +1,336 @@
+package accountclient_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/localhost418/accountclient"
+	"github.com/localhost418/accountclient/generated/models"
+	"github.com/localhost418/accountclient/types"
+)
+
+func TestClientRetry(t *testing.T) {
+	accountID := strfmt.UUID("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+	tt := []struct {
+		name       string
+		policy     *accountclient.RetryPolicy
+		failures   int
+		failStatus int
+		retryAfter string
+		call       func(cli *accountclient.Client) error
+		attempts   int
+		err        error
+		minDelay   time.Duration
+		maxDelay   time.Duration
+	}{
+		{
+			name:       "no policy",
+			failures:   1,
+			failStatus: http.StatusServiceUnavailable,
+			call:       fetchCall(accountID),
+			attempts:   1,
+			err:        accountclient.ErrAPIFailure,
+		},
+		{
+			name:       "fetch succeeds after transient failures",
+			policy:     &accountclient.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, Jitter: 0.5},
+			failures:   2,
+			failStatus: http.StatusBadGateway,
+			call:       fetchCall(accountID),
+			attempts:   3,
+		},
+		{
+			name:       "max attempts reached",
+			policy:     &accountclient.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond},
+			failures:   5,
+			failStatus: http.StatusInternalServerError,
+			call:       fetchCall(accountID),
+			attempts:   2,
+			err:        accountclient.ErrAPIFailure,
+		},
+		{
+			name:       "client errors are not retried",
+			policy:     &accountclient.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond},
+			failures:   1,
+			failStatus: http.StatusBadRequest,
+			call:       fetchCall(accountID),
+			attempts:   1,
+			err:        accountclient.ErrAPIFailure,
+		},
+		{
+			name:       "retry after too many requests",
+			policy:     &accountclient.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Second},
+			failures:   1,
+			failStatus: http.StatusTooManyRequests,
+			retryAfter: "1",
+			call:       fetchCall(accountID),
+			attempts:   2,
+			minDelay:   time.Second,
+		},
+		{
+			name:       "backoff capped after many attempts",
+			policy:     &accountclient.RetryPolicy{MaxAttempts: 80, BaseDelay: 3 * time.Millisecond, MaxDelay: 2 * time.Millisecond, Jitter: 0.1},
+			failures:   79,
+			failStatus: http.StatusServiceUnavailable,
+			call:       fetchCall(accountID),
+			attempts:   80,
+			maxDelay:   2 * time.Millisecond,
+		},
+		{
+			name:       "retry after above max delay",
+			policy:     &accountclient.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: 50 * time.Millisecond},
+			failures:   1,
+			failStatus: http.StatusTooManyRequests,
+			retryAfter: "1",
+			call:       fetchCall(accountID),
+			attempts:   1,
+			err:        accountclient.ErrAPIFailure,
+		},
+		{
+			name:       "delete is retried",
+			policy:     &accountclient.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond},
+			failures:   1,
+			failStatus: http.StatusServiceUnavailable,
+			call: func(cli *accountclient.Client) error {
+				_, err := cli.DeleteAccount(&types.DeleteAccountRequest{AccountID: accountID, Version: 0})
+				return err
+			},
+			attempts: 2,
+		},
+		{
+			name:       "create with account ID is retried",
+			policy:     &accountclient.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond},
+			failures:   1,
+			failStatus: http.StatusServiceUnavailable,
+			call: func(cli *accountclient.Client) error {
+				_, err := cli.CreateAccount(&types.CreateAccountRequest{Data: &models.Account{ID: &accountID}})
+				return err
+			},
+			attempts: 2,
+		},
+		{
+			name:       "create without account ID is not retried",
+			policy:     &accountclient.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond},
+			failures:   1,
+			failStatus: http.StatusServiceUnavailable,
+			call: func(cli *accountclient.Client) error {
+				_, err := cli.CreateAccount(&types.CreateAccountRequest{Data: &models.Account{}})
+				return err
+			},
+			attempts: 1,
+			err:      accountclient.ErrAPIFailure,
+		},
+		{
+			name:       "amend is not retried",
+			policy:     &accountclient.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond},
+			failures:   1,
+			failStatus: http.StatusServiceUnavailable,
+			call: func(cli *accountclient.Client) error {
+				_, err := cli.AmendAccount(&types.AmendAccountRequest{AccountID: accountID})
+				return err
+			},
+			attempts: 1,
+			err:      accountclient.ErrAPIFailure,
+		},
+		{
+			name:       "amend is retried when allowed",
+			policy:     &accountclient.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, RetryNonIdempotent: true},
+			failures:   1,
+			failStatus: http.StatusServiceUnavailable,
+			call: func(cli *accountclient.Client) error {
+				_, err := cli.AmendAccount(&types.AmendAccountRequest{AccountID: accountID})
+				return err
+			},
+			attempts: 2,
+		},
+		{
+			name:     "connection reset is retried",
+			policy:   &accountclient.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond},
+			failures: 2,
+			call:     fetchCall(accountID),
+			attempts: 3,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls int32
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				defer r.Body.Close()
+				if int(atomic.AddInt32(&calls, 1)) <= tc.failures {
+					if tc.failStatus == 0 {
+						// drop the connection without any response
+						conn, _, err := w.(http.Hijacker).Hijack()
+						if err != nil {
+							t.Errorf("cannot hijack connection: %s", err)
+							return
+						}
+						conn.Close()
+						return
+					}
+					if tc.retryAfter != "" {
+						w.Header().Set("Retry-After", tc.retryAfter)
+					}
+					w.WriteHeader(tc.failStatus)
+					return
+				}
+				switch r.Method {
+				case http.MethodPost:
+					w.WriteHeader(http.StatusCreated)
+				case http.MethodDelete:
+					w.WriteHeader(http.StatusNoContent)
+					return
+				default:
+					w.WriteHeader(http.StatusOK)
+				}
+				w.Write([]byte(`{"data":{"id":"ad27e265-9605-4b4b-a0e5-3003ea9cc4dc"}}`))
+			})
+
+			srv := httptest.NewServer(handler)
+			defer srv.Close()
+
+			serverURL, err := url.Parse(srv.URL)
+			if err != nil {
+				t.Fatalf("cannot build server url: %s", err)
+			}
+
+			var attempts []accountclient.Attempt
+			if tc.policy != nil {
+				tc.policy.OnAttempt = func(a accountclient.Attempt) {
+					attempts = append(attempts, a)
+				}
+			}
+
+			cli := accountclient.NewClient(&http.Client{Timeout: time.Second}, *serverURL)
+			cli.SetRetryPolicy(tc.policy)
+
+			err = tc.call(cli)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("unexpected error %v ; expected %v", err, tc.err)
+			}
+			if int(calls) != tc.attempts {
+				t.Fatalf("wrong number of attempts: want %d got %d", tc.attempts, calls)
+			}
+			if tc.policy == nil {
+				return
+			}
+
+			if len(attempts) != tc.attempts {
+				t.Fatalf("wrong number of reported attempts: want %d got %d", tc.attempts, len(attempts))
+			}
+			for i, a := range attempts {
+				last := i == len(attempts)-1
+				if a.Number != i+1 || a.Retry == last || (a.Err == nil) != (last && tc.err == nil) {
+					t.Fatalf("wrong attempt %d reported: %+v", i+1, a)
+				}
+			}
+			if tc.minDelay > 0 && attempts[0].Delay < tc.minDelay {
+				t.Fatalf("Retry-After not honoured: want at least %s got %s", tc.minDelay, attempts[0].Delay)
+			}
+			for _, a := range attempts {
+				if tc.maxDelay > 0 && a.Retry && (a.Delay <= 0 || a.Delay > tc.maxDelay) {
+					t.Fatalf("wrong delay of attempt %d: want at most %s got %s", a.Number, tc.maxDelay, a.Delay)
+				}
+			}
+		})
+	}
+}
+
+func TestClientRetryCancelled(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	serverURL, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatalf("cannot build server url: %s", err)
+	}
+
+	cli := accountclient.NewClient(&http.Client{Timeout: time.Second}, *serverURL)
+	cli.SetRetryPolicy(&accountclient.RetryPolicy{MaxAttempts: 5, BaseDelay: time.Minute})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = cli.FetchAccountWithContext(ctx, &types.FetchAccountRequest{AccountID: "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc"})
+	if !errors.Is(err, accountclient.ErrCancelled) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected error %v ; expected %v", err, accountclient.ErrCancelled)
+	}
+}
+
+func TestClientRetryReplayedCreate(t *testing.T) {
+	accountID := strfmt.UUID("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+	organisationID := strfmt.UUID("eb0bd6f5-c3f5-44b2-b677-acd23cdde73c")
+	tt := []struct {
+		name     string
+		existing string
+		err      error
+	}{
+		{
+			name:     "account created by the first attempt",
+			existing: "GB",
+		},
+		{
+			name:     "other account",
+			existing: "FR",
+			err:      accountclient.ErrAPIFailure,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var posts int32
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				defer r.Body.Close()
+				if r.Method == http.MethodGet {
+					w.Write([]byte(`{"data":{"id":"ad27e265-9605-4b4b-a0e5-3003ea9cc4dc","organisation_id":"eb0bd6f5-c3f5-44b2-b677-acd23cdde73c",` +
+						`"type":"accounts","version":0,"attributes":{"country":"` + tc.existing + `"}}}`))
+					return
+				}
+				// the first attempt creates the account but its response is lost
+				if atomic.AddInt32(&posts, 1) == 1 {
+					w.WriteHeader(http.StatusBadGateway)
+					return
+				}
+				w.WriteHeader(http.StatusConflict)
+			})
+
+			srv := httptest.NewServer(handler)
+			defer srv.Close()
+
+			cli, err := accountclient.NewClientWithOptions(srv.URL, accountclient.WithOrganisationID(organisationID.String()))
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			cli.SetRetryPolicy(&accountclient.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond})
+
+			country := "GB"
+			res, err := cli.CreateAccount(&types.CreateAccountRequest{Data: &models.Account{ID: &accountID, Attributes: &models.AccountAttributes{Country: &country}}})
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("unexpected error %v ; expected %v", err, tc.err)
+			}
+			if tc.err == nil && (res == nil || res.Data == nil || *res.Data.ID != accountID) {
+				t.Fatalf("wrong create response %+v", res)
+			}
+		})
+	}
+}
+
+// fetchCall fetches accountID
+func fetchCall(accountID strfmt.UUID) func(cli *accountclient.Client) error {
+	return func(cli *accountclient.Client) error {
+		_, err := cli.FetchAccount(&types.FetchAccountRequest{AccountID: accountID})
+		return err
+	}
+}
@@ -1,10 +1,46 @@
 package accountclient
 
-import "github.com/localhost418/accountclient/types"
+import (
+	"context"
+
+	"github.com/localhost418/accountclient/types"
+)
+
+// Client must implement Service, EventsService and IdentificationsService
+var (
+	_ Service                = (*Client)(nil)
+	_ EventsService          = (*Client)(nil)
+	_ IdentificationsService = (*Client)(nil)
+)
 
 // Service is the interface for Account ressource operations
 type Service interface {
 	CreateAccount(request *types.CreateAccountRequest) (*types.CreateAccountResponse, error)
 	FetchAccount(request *types.FetchAccountRequest) (*types.FetchAccountResponse, error)
 	DeleteAccount(request *types.DeleteAccountRequest) (*types.DeleteAccountResponse, error)
+	AmendAccount(request *types.AmendAccountRequest) (*types.AmendAccountResponse, error)
+	ListAccounts(request *types.ListAccountsRequest) (*types.ListAccountsResponse, error)
+	CreateAccountWithContext(ctx context.Context, request *types.CreateAccountRequest) (*types.CreateAccountResponse, error)
+	FetchAccountWithContext(ctx context.Context, request *types.FetchAccountRequest) (*types.FetchAccountResponse, error)
+	DeleteAccountWithContext(ctx context.Context, request *types.DeleteAccountRequest) (*types.DeleteAccountResponse, error)
+	AmendAccountWithContext(ctx context.Context, request *types.AmendAccountRequest) (*types.AmendAccountResponse, error)
+	ListAccountsWithContext(ctx context.Context, request *types.ListAccountsRequest) (*types.ListAccountsResponse, error)
+}
+
+// EventsService is the interface for Account event ressource operations
+type EventsService interface {
+	FetchAccountEvents(request *types.FetchAccountEventsRequest) (*types.FetchAccountEventsResponse, error)
+	FetchAccountEventsWithContext(ctx context.Context, request *types.FetchAccountEventsRequest) (*types.FetchAccountEventsResponse, error)
+}
+
+// IdentificationsService is the interface for Account identification ressource operations
+type IdentificationsService interface {
+	CreateAccountIdentification(request *types.CreateAccountIdentificationRequest) (*types.CreateAccountIdentificationResponse, error)
+	FetchAccountIdentification(request *types.FetchAccountIdentificationRequest) (*types.FetchAccountIdentificationResponse, error)
+	DeleteAccountIdentification(request *types.DeleteAccountIdentificationRequest) (*types.DeleteAccountIdentificationResponse, error)
+	ListAccountIdentifications(request *types.ListAccountIdentificationsRequest) (*types.ListAccountIdentificationsResponse, error)
+	CreateAccountIdentificationWithContext(ctx context.Context, request *types.CreateAccountIdentificationRequest) (*types.CreateAccountIdentificationResponse, error)
+	FetchAccountIdentificationWithContext(ctx context.Context, request *types.FetchAccountIdentificationRequest) (*types.FetchAccountIdentificationResponse, error)
+	DeleteAccountIdentificationWithContext(ctx context.Context, request *types.DeleteAccountIdentificationRequest) (*types.DeleteAccountIdentificationResponse, error)
+	ListAccountIdentificationsWithContext(ctx context.Context, request *types.ListAccountIdentificationsRequest) (*types.ListAccountIdentificationsResponse, error)
 }
@@ -0,0 +1,118 @@
+/*
+Package signing signs the requests sent to the account API with HTTP message signatures
+(draft-cavage-http-signatures) using a key registered on /platform/security/signing_keys.
+*/
+package signing
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// AlgorithmRSA is the algorithm of the signatures made with an RSA key
+	AlgorithmRSA = "rsa-sha256"
+
+	// AlgorithmECDSA is the algorithm of the signatures made with an ECDSA key
+	AlgorithmECDSA = "ecdsa-sha256"
+
+	// RequestTarget is the pseudo header covering the method, path and query of the request
+	RequestTarget = "(request-target)"
+)
+
+// DefaultHeaders are the headers covered by the signature
+var DefaultHeaders = []string{RequestTarget, "date", "digest", "host"}
+
+// Signer adds the Digest and Signature headers (and Date when missing) to the requests
+type Signer struct {
+	keyID     string
+	key       crypto.Signer
+	algorithm string
+	now       func() time.Time
+}
+
+// NewSigner creates a Signer with the private key registered as keyID (*rsa.PrivateKey or *ecdsa.PrivateKey)
+func NewSigner(keyID string, key crypto.Signer) (*Signer, error) {
+	if keyID == "" {
+		return nil, errors.New("empty key id")
+	}
+	s := &Signer{keyID: keyID, key: key, now: time.Now}
+	switch key.(type) {
+	case *rsa.PrivateKey:
+		s.algorithm = AlgorithmRSA
+	case *ecdsa.PrivateKey:
+		s.algorithm = AlgorithmECDSA
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", key)
+	}
+	return s, nil
+}
+
+// KeyID returns the ID of the signing key
+func (s *Signer) KeyID() string {
+	return s.keyID
+}
+
+// Sign signs r, body is the request body as sent (nil when there is none)
+func (s *Signer) Sign(r *http.Request, body []byte) error {
+	if r.Header.Get("Date") == "" {
+		r.Header.Set("Date", s.now().UTC().Format(http.TimeFormat))
+	}
+	r.Header.Set("Digest", Digest(body))
+
+	signingString, err := buildSigningString(r, DefaultHeaders)
+	if err != nil {
+		return err
+	}
+	hashed := sha256.Sum256([]byte(signingString))
+	// rsa keys sign with PKCS #1 v1.5, ecdsa keys produce an ASN.1 signature
+	signature, err := s.key.Sign(rand.Reader, hashed[:], crypto.SHA256)
+	if err != nil {
+		return fmt.Errorf("cannot sign request: %w", err)
+	}
+
+	r.Header.Set("Signature", fmt.Sprintf(`keyId="%s",algorithm="%s",headers="%s",signature="%s"`,
+		s.keyID, s.algorithm, strings.Join(DefaultHeaders, " "), base64.StdEncoding.EncodeToString(signature)))
+	return nil
+}
+
+// Digest returns the Digest header value of body
+func Digest(body []byte) string {
+	sum := sha256.Sum256(body)
+	return "SHA-256=" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// buildSigningString builds the string covered by the signature from the headers of r
+func buildSigningString(r *http.Request, headers []string) (string, error) {
+	lines := make([]string, 0, len(headers))
+	for _, h := range headers {
+		h = strings.ToLower(h)
+		var value string
+		switch h {
+		case RequestTarget:
+			value = strings.ToLower(r.Method) + " " + r.URL.RequestURI()
+		case "host":
+			value = r.Host
+			if value == "" {
+				value = r.URL.Host
+			}
+		default:
+			values := r.Header.Values(h)
+			if len(values) == 0 {
+				return "", fmt.Errorf("missing signed header %s", h)
+			}
+			value = strings.Join(values, ", ")
+		}
+		lines = append(lines, h+": "+strings.TrimSpace(value))
+	}
+	return strings.Join(lines, "\n"), nil
+}
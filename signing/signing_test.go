@@ -0,0 +1,193 @@
+package signing_test
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/localhost418/accountclient/signing"
+)
+
+func TestSignVerify(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("cannot generate rsa key: %s", err)
+	}
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("cannot generate ecdsa key: %s", err)
+	}
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("cannot generate ecdsa key: %s", err)
+	}
+
+	body := []byte(`{"data":{"id":"ad27e265-9605-4b4b-a0e5-3003ea9cc4dc"}}`)
+	tt := []struct {
+		name   string
+		key    crypto.Signer
+		keyID  string
+		keys   map[string]crypto.PublicKey
+		tamper func(r *http.Request)
+		err    string
+	}{
+		{
+			name:  "rsa",
+			key:   rsaKey,
+			keyID: "rsa-key",
+			keys:  map[string]crypto.PublicKey{"rsa-key": &rsaKey.PublicKey},
+		},
+		{
+			name:  "ecdsa",
+			key:   ecdsaKey,
+			keyID: "ecdsa-key",
+			keys:  map[string]crypto.PublicKey{"ecdsa-key": &ecdsaKey.PublicKey},
+		},
+		{
+			name:  "unknown key id",
+			key:   ecdsaKey,
+			keyID: "ecdsa-key",
+			keys:  map[string]crypto.PublicKey{"other-key": &ecdsaKey.PublicKey},
+			err:   "unknown key id",
+		},
+		{
+			name:  "wrong key",
+			key:   ecdsaKey,
+			keyID: "ecdsa-key",
+			keys:  map[string]crypto.PublicKey{"ecdsa-key": &otherKey.PublicKey},
+			err:   "invalid signature",
+		},
+		{
+			name:  "algorithm mismatch",
+			key:   ecdsaKey,
+			keyID: "key",
+			keys:  map[string]crypto.PublicKey{"key": &rsaKey.PublicKey},
+			err:   "does not match the key",
+		},
+		{
+			name:  "tampered body",
+			key:   rsaKey,
+			keyID: "rsa-key",
+			keys:  map[string]crypto.PublicKey{"rsa-key": &rsaKey.PublicKey},
+			tamper: func(r *http.Request) {
+				r.Body = httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{}")).Body
+			},
+			err: "digest",
+		},
+		{
+			name:   "tampered path",
+			key:    rsaKey,
+			keyID:  "rsa-key",
+			keys:   map[string]crypto.PublicKey{"rsa-key": &rsaKey.PublicKey},
+			tamper: func(r *http.Request) { r.URL.Path = "/v1/organisation/accounts/other" },
+			err:    "invalid signature",
+		},
+		{
+			name:   "tampered date",
+			key:    rsaKey,
+			keyID:  "rsa-key",
+			keys:   map[string]crypto.PublicKey{"rsa-key": &rsaKey.PublicKey},
+			tamper: func(r *http.Request) { r.Header.Set("Date", time.Now().Add(time.Second).UTC().Format(http.TimeFormat)) },
+			err:    "invalid signature",
+		},
+		{
+			name:   "missing signature",
+			key:    rsaKey,
+			keyID:  "rsa-key",
+			keys:   map[string]crypto.PublicKey{"rsa-key": &rsaKey.PublicKey},
+			tamper: func(r *http.Request) { r.Header.Del("Signature") },
+			err:    "missing signature",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			signer, err := signing.NewSigner(tc.keyID, tc.key)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			r := httptest.NewRequest(http.MethodPost, "http://api.form3.tech/v1/organisation/accounts?version=1", bytes.NewReader(body))
+			if err := signer.Sign(r, body); err != nil {
+				t.Fatalf("unexpected sign error: %s", err)
+			}
+			for _, h := range []string{"Date", "Digest", "Signature"} {
+				if r.Header.Get(h) == "" {
+					t.Fatalf("missing %s header", h)
+				}
+			}
+			if tc.tamper != nil {
+				tc.tamper(r)
+			}
+
+			v := &signing.Verifier{Keys: tc.keys, MaxSkew: time.Minute}
+			err = v.Verify(r)
+			if (err != nil) != (tc.err != "") || (err != nil && !strings.Contains(err.Error(), tc.err)) {
+				t.Fatalf("unexpected error %v ; expected %q", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestNewSignerErrors(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("cannot generate ed25519 key: %s", err)
+	}
+	if _, err := signing.NewSigner("key", edKey); err == nil {
+		t.Fatal("expected an error for an unsupported key type")
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("cannot generate rsa key: %s", err)
+	}
+	if _, err := signing.NewSigner("", rsaKey); err == nil {
+		t.Fatal("expected an error for an empty key id")
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("cannot generate ecdsa key: %s", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(ecdsaKey)
+	if err != nil {
+		t.Fatalf("cannot marshal private key: %s", err)
+	}
+	private, err := signing.ParsePrivateKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	if err != nil || !ecdsaKey.Equal(private) {
+		t.Fatalf("cannot parse private key: %v", err)
+	}
+
+	der, err = x509.MarshalPKIXPublicKey(&ecdsaKey.PublicKey)
+	if err != nil {
+		t.Fatalf("cannot marshal public key: %s", err)
+	}
+	public, err := signing.ParsePublicKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	if err != nil || !ecdsaKey.PublicKey.Equal(public) {
+		t.Fatalf("cannot parse public key: %v", err)
+	}
+
+	if _, err := signing.ParsePrivateKey([]byte("not a key")); err == nil {
+		t.Fatal("expected an error for an invalid key")
+	}
+	// a malformed key is a nil signer, not a nil key wrapped in the interface
+	for _, typ := range []string{"RSA PRIVATE KEY", "EC PRIVATE KEY", "PRIVATE KEY"} {
+		signer, err := signing.ParsePrivateKey(pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: []byte("garbage")}))
+		if err == nil || signer != nil {
+			t.Fatalf("unexpected signer %#v for a malformed %s (error %v)", signer, typ, err)
+		}
+	}
+}
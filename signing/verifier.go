@@ -0,0 +1,130 @@
+package signing
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Verifier checks the signatures of the requests made by a Signer, for tests and local stubs of the API
+type Verifier struct {
+	// Keys are the public keys by key ID
+	Keys map[string]crypto.PublicKey
+
+	// MaxSkew rejects the requests whose Date is further than MaxSkew from now (not checked when zero)
+	MaxSkew time.Duration
+}
+
+// Verify checks the Digest and Signature headers of r, r.Body is read and replaced so the request can still be handled
+func (v *Verifier) Verify(r *http.Request) error {
+	params, err := parseSignature(r.Header.Get("Signature"))
+	if err != nil {
+		return err
+	}
+	key, ok := v.Keys[params["keyId"]]
+	if !ok {
+		return fmt.Errorf("unknown key id %q", params["keyId"])
+	}
+	headers := strings.Fields(params["headers"])
+	if len(headers) == 0 {
+		headers = []string{"date"}
+	}
+	for _, required := range DefaultHeaders {
+		if !contains(headers, required) {
+			return fmt.Errorf("header %s is not signed", required)
+		}
+	}
+
+	var body []byte
+	if r.Body != nil {
+		body, err = io.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			return fmt.Errorf("cannot read body: %w", err)
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	if r.Header.Get("Digest") != Digest(body) {
+		return errors.New("digest does not match the body")
+	}
+
+	if v.MaxSkew > 0 {
+		date, err := http.ParseTime(r.Header.Get("Date"))
+		if err != nil {
+			return fmt.Errorf("invalid date: %w", err)
+		}
+		if skew := time.Since(date); skew > v.MaxSkew || skew < -v.MaxSkew {
+			return fmt.Errorf("date skew %s exceeds %s", skew, v.MaxSkew)
+		}
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(params["signature"])
+	if err != nil {
+		return fmt.Errorf("invalid signature encoding: %w", err)
+	}
+	signingString, err := buildSigningString(r, headers)
+	if err != nil {
+		return err
+	}
+	hashed := sha256.Sum256([]byte(signingString))
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if params["algorithm"] != AlgorithmRSA {
+			return fmt.Errorf("algorithm %q does not match the key", params["algorithm"])
+		}
+		if err := rsa.VerifyPKCS1v15(k, crypto.SHA256, hashed[:], signature); err != nil {
+			return errors.New("invalid signature")
+		}
+	case *ecdsa.PublicKey:
+		if params["algorithm"] != AlgorithmECDSA {
+			return fmt.Errorf("algorithm %q does not match the key", params["algorithm"])
+		}
+		if !ecdsa.VerifyASN1(k, hashed[:], signature) {
+			return errors.New("invalid signature")
+		}
+	default:
+		return fmt.Errorf("unsupported key type %T", key)
+	}
+	return nil
+}
+
+// parseSignature parses the key="value" parameters of a Signature header
+func parseSignature(header string) (map[string]string, error) {
+	if header == "" {
+		return nil, errors.New("missing signature header")
+	}
+	params := map[string]string{}
+	for _, p := range strings.Split(header, ",") {
+		i := strings.Index(p, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid signature parameter %q", p)
+		}
+		params[strings.TrimSpace(p[:i])] = strings.Trim(strings.TrimSpace(p[i+1:]), `"`)
+	}
+	for _, required := range []string{"keyId", "algorithm", "signature"} {
+		if params[required] == "" {
+			return nil, fmt.Errorf("missing signature parameter %s", required)
+		}
+	}
+	return params, nil
+}
+
+// contains tells whether values contains value
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package accountclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	"github.com/localhost418/accountclient/generated/models"
+	"github.com/localhost418/accountclient/types"
+)
+
+// CloseAccount closes an account with reason (optional) and returns the amended account
+func (c *Client) CloseAccount(accountID strfmt.UUID, version int, reason types.AccountStatusReason) (*types.AmendAccountResponse, error) {
+	return c.CloseAccountWithContext(context.Background(), accountID, version, reason)
+}
+
+/*
+CloseAccountWithContext closes an account with reason (optional) and returns the amended account, bound to ctx.
+The account is fetched to check it can be closed from its current status (ErrInvalidTransition otherwise)
+and that it is still at version (ErrVersionConflict otherwise), then amended with the closed status and reason only.
+*/
+func (c *Client) CloseAccountWithContext(ctx context.Context, accountID strfmt.UUID, version int, reason types.AccountStatusReason) (*types.AmendAccountResponse, error) {
+	const method = http.MethodPatch
+	endpoint := c.accountsPath()
+
+	fetched, err := c.FetchAccountWithContext(ctx, &types.FetchAccountRequest{AccountID: accountID})
+	if err != nil {
+		return nil, err
+	}
+	account := fetched.Data
+	if account == nil || account.Attributes == nil {
+		return nil, NewAccountError(ErrInvalidResponse, http.MethodGet, endpoint, http.StatusOK, nil)
+	}
+
+	current := types.AccountStatus(account.Attributes.Status)
+	if err := types.ValidateStatusTransition(current, types.AccountStatusClosed, reason); err != nil {
+		return nil, NewAccountError(ErrInvalidTransition, method, endpoint, 0, err)
+	}
+	if account.Version == nil || *account.Version != int64(version) {
+		err := fmt.Errorf("account is at version %d, not %d", swag.Int64Value(account.Version), version)
+		return nil, NewAccountError(ErrVersionConflict, method, endpoint, 0, err)
+	}
+
+	// an amend is partial: only the status changes, the attributes changed since the fetch are left alone
+	return c.AmendAccountWithContext(ctx, &types.AmendAccountRequest{
+		AccountID: accountID,
+		Version:   version,
+		Attributes: &models.AccountAttributes{
+			Status:       models.AccountAttributesStatusClosed,
+			StatusReason: string(reason),
+		},
+	})
+}
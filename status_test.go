@@ -0,0 +1,120 @@
+package accountclient_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/localhost418/accountclient"
+	"github.com/localhost418/accountclient/accountapitest"
+	"github.com/localhost418/accountclient/generated/models"
+	"github.com/localhost418/accountclient/types"
+)
+
+func TestValidateStatusTransition(t *testing.T) {
+	tt := []struct {
+		from   types.AccountStatus
+		to     types.AccountStatus
+		reason types.AccountStatusReason
+		valid  bool
+	}{
+		{from: "", to: types.AccountStatusConfirmed, valid: true},
+		{from: types.AccountStatusPending, to: types.AccountStatusConfirmed, valid: true},
+		{from: types.AccountStatusPending, to: types.AccountStatusFailed, valid: true},
+		{from: types.AccountStatusFailed, to: types.AccountStatusPending, valid: true},
+		{from: types.AccountStatusConfirmed, to: types.AccountStatusClosed, reason: types.AccountStatusReasonDeceased, valid: true},
+		{from: types.AccountStatusClosed, to: types.AccountStatusClosed, reason: types.AccountStatusReasonTransferred, valid: true},
+		{from: types.AccountStatusConfirmed, to: types.AccountStatusConfirmed, valid: true},
+		{from: types.AccountStatusClosed, to: types.AccountStatusPending},
+		{from: types.AccountStatusConfirmed, to: types.AccountStatusFailed},
+		{from: types.AccountStatusConfirmed, to: types.AccountStatusPending},
+		{from: types.AccountStatusPending, to: types.AccountStatusConfirmed, reason: types.AccountStatusReasonStopped},
+		{from: types.AccountStatusConfirmed, to: types.AccountStatusClosed, reason: "retired"},
+		{from: "opened", to: types.AccountStatusClosed},
+		{from: types.AccountStatusPending, to: "opened"},
+	}
+
+	for _, tc := range tt {
+		t.Run(string(tc.from)+"->"+string(tc.to)+"/"+string(tc.reason), func(t *testing.T) {
+			err := types.ValidateStatusTransition(tc.from, tc.to, tc.reason)
+			if (err == nil) != tc.valid {
+				t.Fatalf("unexpected error %v ; expected valid %t", err, tc.valid)
+			}
+		})
+	}
+}
+
+func TestClientCloseAccount(t *testing.T) {
+	organisationID := strfmt.UUID("eb0bd6f5-c3f5-44b2-b677-acd23cdde73c")
+	country := "GB"
+	tt := []struct {
+		name    string
+		status  string
+		version int
+		reason  types.AccountStatusReason
+		err     error
+	}{
+		{name: "confirmed", status: models.AccountAttributesStatusConfirmed, reason: types.AccountStatusReasonTransferred},
+		{name: "pending without reason", status: models.AccountAttributesStatusPending},
+		{name: "unknown reason", status: models.AccountAttributesStatusConfirmed, reason: "retired", err: accountclient.ErrInvalidTransition},
+		{name: "stale version", status: models.AccountAttributesStatusConfirmed, version: 3, err: accountclient.ErrVersionConflict},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := accountapitest.NewServer()
+			defer srv.Close()
+			var patch string
+			cli, err := accountclient.NewClientWithOptions(srv.URL, accountclient.WithInterceptors(
+				func(inv *accountclient.Invocation, next accountclient.Handler) (*http.Response, error) {
+					if inv.HTTPRequest.Method == http.MethodPatch {
+						body, _ := inv.HTTPRequest.GetBody()
+						b, _ := io.ReadAll(body)
+						patch = string(b)
+					}
+					return next(inv)
+				}))
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			accountID := strfmt.UUID("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+			_, err = cli.CreateAccount(&types.CreateAccountRequest{Data: &models.Account{
+				ID:             &accountID,
+				OrganisationID: &organisationID,
+				Type:           "accounts",
+				Attributes:     &models.AccountAttributes{Country: &country, Name: []string{"Jane Doe"}, Status: tc.status},
+			}})
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			res, err := cli.CloseAccount(accountID, tc.version, tc.reason)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("unexpected error %v ; expected %v", err, tc.err)
+			}
+			if tc.err != nil {
+				return
+			}
+			attrs := res.Data.Attributes
+			if attrs.Status != models.AccountAttributesStatusClosed || attrs.StatusReason != string(tc.reason) || *res.Data.Version != 1 {
+				t.Fatalf("account not closed: status %s reason %s version %d", attrs.Status, attrs.StatusReason, *res.Data.Version)
+			}
+			if len(attrs.Name) != 1 || attrs.Name[0] != "Jane Doe" || *attrs.Country != country {
+				t.Fatalf("account attributes not kept: %+v", attrs)
+			}
+			// only the status is sent, so a concurrent change of another attribute is kept
+			want := `"attributes":{"status":"closed"}}}`
+			if tc.reason != "" {
+				want = `"attributes":{"status":"closed","status_reason":"` + string(tc.reason) + `"}}}`
+			}
+			if !strings.Contains(patch, want) {
+				t.Fatalf("wrong amend body: want %s got %s", want, patch)
+			}
+
+		})
+	}
+}
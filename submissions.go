@@ -0,0 +1,372 @@
+package accountclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"path"
+	"reflect"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/localhost418/accountclient/types"
+)
+
+// PollOptions configures the polling of a submission status until it is terminal,
+// the polling is bounded by the deadline of its context
+type PollOptions struct {
+	// Interval is the delay before the first poll (1s when zero)
+	Interval time.Duration
+
+	// MaxInterval caps the delay between two polls (30s when zero)
+	MaxInterval time.Duration
+
+	// Multiplier grows the delay after each poll (2 when lower than 1)
+	Multiplier float64
+}
+
+// DefaultPollOptions returns the options used when polling with nil options
+func DefaultPollOptions() *PollOptions {
+	return &PollOptions{
+		Interval:    time.Second,
+		MaxInterval: 30 * time.Second,
+		Multiplier:  2,
+	}
+}
+
+// withDefaults fills the zero fields of o (nil-safe)
+func (o *PollOptions) withDefaults() PollOptions {
+	p := *DefaultPollOptions()
+	if o == nil {
+		return p
+	}
+	if o.Interval > 0 {
+		p.Interval = o.Interval
+	}
+	if o.MaxInterval > 0 {
+		p.MaxInterval = o.MaxInterval
+	}
+	if o.Multiplier >= 1 {
+		p.Multiplier = o.Multiplier
+	}
+	return p
+}
+
+// pollSubmission calls fetch with a growing delay until the status it returns is terminal, fetch fails or ctx is done
+func pollSubmission(ctx context.Context, opts *PollOptions, method, endpoint string, fetch func(ctx context.Context) (types.SubmissionStatus, error)) error {
+	o := opts.withDefaults()
+	delay := o.Interval
+	for {
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return NewAccountError(ErrCancelled, method, endpoint, 0, ctx.Err())
+		case <-timer.C:
+		}
+
+		status, err := fetch(ctx)
+		if err != nil {
+			return err
+		}
+		if status.Terminal() {
+			return nil
+		}
+
+		delay = time.Duration(float64(delay) * o.Multiplier)
+		if delay > o.MaxInterval {
+			delay = o.MaxInterval
+		}
+	}
+}
+
+// submissionsPath is the path of the submissions of a ressource, under the ressource path
+const submissionsPath = "submissions"
+
+/*
+schemeResource holds the lifecycle shared by the ressources submitted to the scheme (account amendments and account requests):
+the defaulting of their ID, organisation ID and type, their creation and fetch, the creation, fetch and polling of their submissions,
+Submit and the status of its outcome. The typed clients wrap it with their request and response types.
+*/
+type schemeResource struct {
+	client       *Client
+	resourcePath string
+	operations   schemeOperations
+	calls        schemeCalls
+}
+
+// schemeOperations are the operation names of the calls of a schemeResource
+type schemeOperations struct {
+	create           string
+	fetch            string
+	createSubmission string
+	fetchSubmission  string
+}
+
+// path is the path of the ressources
+func (s *schemeResource) path() string {
+	return path.Join(s.client.apiPath, s.resourcePath)
+}
+
+// noRequest is the error of a nil request
+func (s *schemeResource) noRequest(method string) *AccountError {
+	return NewAccountError(ErrNoRequest, method, s.path(), 0, nil)
+}
+
+// defaults sets the ID, organisation ID and type of a ressource to create when they are not set
+func (s *schemeResource) defaults(id, organisationID *strfmt.UUID, typ *string, defaultType string) *AccountError {
+	if *id == "" {
+		generated, err := newUUID()
+		if err != nil {
+			return NewAccountError(ErrInvalidRequest, http.MethodPost, s.path(), 0, err)
+		}
+		*id = generated
+	}
+	if *organisationID == "" {
+		*organisationID = s.client.organisationID
+	}
+	if *typ == "" {
+		*typ = defaultType
+	}
+	return nil
+}
+
+// create POSTs req, the ressource id (empty without data), and resolves the 409 of a replayed POST with fetchReq, see replayed.
+// A second POST with the same ressource ID is rejected so it is idempotent when its data is set
+func (s *schemeResource) create(ctx context.Context, req io.WriterTo, id strfmt.UUID, fetchReq interface{}, res io.ReaderFrom) *AccountError {
+	call := &apiCall{
+		operation:  s.operations.create,
+		request:    req,
+		method:     http.MethodPost,
+		endpoint:   s.path(),
+		paths:      []string{s.path()},
+		body:       req,
+		status:     http.StatusCreated,
+		idempotent: id != "",
+		response:   res,
+	}
+	return s.replayed(ctx, call, func() *AccountError {
+		return s.fetch(ctx, fetchReq, id, res)
+	})
+}
+
+// fetch GETs the ressource id
+func (s *schemeResource) fetch(ctx context.Context, req interface{}, id strfmt.UUID, res io.ReaderFrom) *AccountError {
+	return s.client.send(ctx, &apiCall{
+		operation:  s.operations.fetch,
+		request:    req,
+		method:     http.MethodGet,
+		endpoint:   s.path(),
+		paths:      []string{s.path(), id.String()},
+		status:     http.StatusOK,
+		idempotent: true,
+		response:   res,
+	})
+}
+
+// createSubmission POSTs req, the submission submissionID of the ressource id,
+// and resolves the 409 of a replayed POST with fetchReq, see replayed
+func (s *schemeResource) createSubmission(ctx context.Context, req io.WriterTo, id, submissionID strfmt.UUID, fetchReq interface{}, res io.ReaderFrom) *AccountError {
+	call := &apiCall{
+		operation:  s.operations.createSubmission,
+		request:    req,
+		method:     http.MethodPost,
+		endpoint:   s.path(),
+		paths:      []string{s.path(), id.String(), submissionsPath},
+		body:       req,
+		status:     http.StatusCreated,
+		idempotent: true,
+		response:   res,
+	}
+	return s.replayed(ctx, call, func() *AccountError {
+		return s.fetchSubmission(ctx, fetchReq, id, submissionID, res)
+	})
+}
+
+/*
+replayed sends call, a create of a ressource with its ID set, and resolves the 409 of a retry: the ressource is possibly created by
+an attempt whose response was lost, so it is fetched into the call response and kept if it has the organisation and attributes
+of the call body, otherwise the conflict is returned.
+*/
+func (s *schemeResource) replayed(ctx context.Context, call *apiCall, fetch func() *AccountError) *AccountError {
+	err := s.client.send(ctx, call)
+	if err == nil || !call.idempotent || call.attempts < 2 || err.StatusCode != http.StatusConflict {
+		return err
+	}
+	if fetch() != nil || !sameResource(call.body, call.response) {
+		return err
+	}
+	return nil
+}
+
+// schemeData is the part of a ressource compared by sameResource
+type schemeData struct {
+	Data *struct {
+		OrganisationID strfmt.UUID            `json:"organisation_id"`
+		Attributes     map[string]interface{} `json:"attributes"`
+	} `json:"data"`
+}
+
+// sameResource tells whether the ressource of res has the organisation of the ressource of req and its set attributes
+func sameResource(req io.WriterTo, res io.ReaderFrom) bool {
+	var want, got schemeData
+	buf := &bytes.Buffer{}
+	if _, err := req.WriteTo(buf); err != nil || json.Unmarshal(buf.Bytes(), &want) != nil {
+		return false
+	}
+	b, err := json.Marshal(res)
+	if err != nil || json.Unmarshal(b, &got) != nil {
+		return false
+	}
+	if want.Data == nil || got.Data == nil || want.Data.OrganisationID != got.Data.OrganisationID {
+		return false
+	}
+	for name, value := range want.Data.Attributes {
+		if !reflect.DeepEqual(got.Data.Attributes[name], value) {
+			return false
+		}
+	}
+	return true
+}
+
+// fetchSubmission GETs the submission submissionID of the ressource id
+func (s *schemeResource) fetchSubmission(ctx context.Context, req interface{}, id, submissionID strfmt.UUID, res io.ReaderFrom) *AccountError {
+	return s.client.send(ctx, &apiCall{
+		operation:  s.operations.fetchSubmission,
+		request:    req,
+		method:     http.MethodGet,
+		endpoint:   s.path(),
+		paths:      []string{s.path(), id.String(), submissionsPath, submissionID.String()},
+		status:     http.StatusOK,
+		idempotent: true,
+		response:   res,
+	})
+}
+
+// schemeCalls are the typed calls of the lifecycle of a ressource, made by its typed client with its request and response types.
+// They return the ressource or submission of the response (e.g. *types.AccountAmendment), a nil pointer when it has none
+type schemeCalls struct {
+	// create creates the ressource of req, a typed create request
+	create func(ctx context.Context, req interface{}) (interface{}, error)
+
+	// createSubmission submits the ressource id of organisationID
+	createSubmission func(ctx context.Context, id, organisationID strfmt.UUID) (interface{}, error)
+
+	// fetchSubmission fetches the submission submissionID of the ressource id
+	fetchSubmission func(ctx context.Context, id, submissionID strfmt.UUID) (interface{}, error)
+}
+
+// resourceIDs returns the ID and organisation ID of a ressource of the scheme, empty when it is nil
+func resourceIDs(resource interface{}) (strfmt.UUID, strfmt.UUID) {
+	switch r := resource.(type) {
+	case *types.AccountAmendment:
+		if r != nil {
+			return r.ID, r.OrganisationID
+		}
+	case *types.AccountRequest:
+		if r != nil {
+			return r.ID, r.OrganisationID
+		}
+	}
+	return "", ""
+}
+
+// submissionState returns the ID and attributes of a submission to the scheme, ok is false when it is nil
+func submissionState(submission interface{}) (id strfmt.UUID, attributes *types.SubmissionAttributes, ok bool) {
+	switch s := submission.(type) {
+	case *types.AccountAmendmentSubmission:
+		if s != nil {
+			return s.ID, s.Attributes, true
+		}
+	case *types.AccountRequestSubmission:
+		if s != nil {
+			return s.ID, s.Attributes, true
+		}
+	}
+	return "", nil, false
+}
+
+// submissionOutcome is embedded in the outcomes of Submit, it holds the attributes of the last known state of their submission
+type submissionOutcome struct {
+	attributes *types.SubmissionAttributes
+}
+
+// Status returns the last known status of the submission (empty before the submission is created)
+func (o *submissionOutcome) Status() types.SubmissionStatus {
+	if o.attributes == nil {
+		return ""
+	}
+	return o.attributes.Status
+}
+
+// Reason returns the status reason of the submission, e.g. why its delivery failed
+func (o *submissionOutcome) Reason() string {
+	if o.attributes == nil {
+		return ""
+	}
+	return o.attributes.StatusReason
+}
+
+// Confirmed tells whether the scheme confirmed the delivery of the submitted ressource
+func (o *submissionOutcome) Confirmed() bool {
+	return o.Status() == types.SubmissionDeliveryConfirmed
+}
+
+// pollSubmission fetches the submission submissionID of the ressource id until its status is terminal, see pollSubmission.
+// It returns the last fetched submission, even on error, nil when none was fetched
+func (s *schemeResource) pollSubmission(ctx context.Context, id, submissionID strfmt.UUID, opts *PollOptions) (interface{}, error) {
+	var submission interface{}
+	err := pollSubmission(ctx, opts, http.MethodGet, s.path(), func(ctx context.Context) (types.SubmissionStatus, error) {
+		fetched, err := s.calls.fetchSubmission(ctx, id, submissionID)
+		if err != nil {
+			return "", err
+		}
+		_, attributes, ok := submissionState(fetched)
+		if !ok {
+			return "", nil
+		}
+		submission = fetched
+		if attributes == nil {
+			return "", nil
+		}
+		return attributes.Status, nil
+	})
+	return submission, err
+}
+
+/*
+submit creates the ressource of req, submits it and polls the submission until its status is terminal, recording the state of the
+submission in outcome. It returns the created ressource and the last known submission, so far on error.
+*/
+func (s *schemeResource) submit(ctx context.Context, req interface{}, opts *PollOptions, outcome *submissionOutcome) (interface{}, interface{}, error) {
+	resource, err := s.calls.create(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	id, organisationID := resourceIDs(resource)
+	if id == "" {
+		return resource, nil, NewAccountError(ErrInvalidResponse, http.MethodPost, s.path(), http.StatusCreated, nil)
+	}
+
+	submission, err := s.calls.createSubmission(ctx, id, organisationID)
+	if err != nil {
+		return resource, nil, err
+	}
+	submissionID, attributes, _ := submissionState(submission)
+	if submissionID == "" {
+		return resource, submission, NewAccountError(ErrInvalidResponse, http.MethodPost, s.path(), http.StatusCreated, nil)
+	}
+	outcome.attributes = attributes
+	if outcome.Status().Terminal() {
+		return resource, submission, nil
+	}
+
+	polled, err := s.pollSubmission(ctx, id, submissionID, opts)
+	if polled != nil {
+		submission = polled
+		_, outcome.attributes, _ = submissionState(polled)
+	}
+	return resource, submission, err
+}
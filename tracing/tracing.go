@@ -0,0 +1,223 @@
+/*
+Package tracing instruments the Client with OpenTelemetry: every operation is a client span, with an event per attempt,
+whose context is sent to the API in the W3C traceparent header.
+
+	cli, err := accountclient.NewClientWithOptions(baseURL, tracing.Instrument(nil))
+*/
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/localhost418/accountclient"
+	"github.com/localhost418/accountclient/generated/models"
+	"github.com/localhost418/accountclient/types"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName is the name of the tracer
+const instrumentationName = "github.com/localhost418/accountclient/tracing"
+
+// AttemptEvent is the name of the span events of the attempts
+const AttemptEvent = "attempt"
+
+// span attributes of the operations, next to the HTTP method, URL and status code
+const (
+	OperationKey      = attribute.Key("account.operation")
+	AttemptKey        = attribute.Key("account.attempt")
+	AccountIDKey      = attribute.Key("account.id")
+	OrganisationIDKey = attribute.Key("account.organisation_id")
+	ErrorKindKey      = attribute.Key("account.error_kind")
+)
+
+// Options of the tracing instrumentation
+type Options struct {
+	// TracerProvider makes the tracer of the spans, the global one (otel.GetTracerProvider) when nil
+	TracerProvider trace.TracerProvider
+
+	// Propagator injects the span context in the request headers, the W3C trace context (traceparent and tracestate) when nil
+	Propagator propagation.TextMapPropagator
+}
+
+/*
+Instrument returns the option tracing the operations of a Client, opts is optional.
+The span of an operation is named after it (e.g. "accountclient.CreateAccount") and is a child of the span of the operation context.
+It has the account ID of the request, the organisation ID of the request or response (the client one otherwise),
+the number of attempts, the last response status code and, on failure, the AccountError kind.
+Each attempt adds an AttemptEvent with its number, status code and error kind.
+*/
+func Instrument(opts *Options) accountclient.Option {
+	if opts == nil {
+		opts = &Options{}
+	}
+	tp := opts.TracerProvider
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	var propagator propagation.TextMapPropagator = propagation.TraceContext{}
+	if opts.Propagator != nil {
+		propagator = opts.Propagator
+	}
+	t := &tracer{tracer: tp.Tracer(instrumentationName), propagator: propagator}
+
+	return func(c *accountclient.Client) error {
+		if err := accountclient.WithOperationHooks(t.hook(c))(c); err != nil {
+			return err
+		}
+		return accountclient.WithInterceptors(t.intercept)(c)
+	}
+}
+
+// tracer starts the spans of the operations and records their attempts
+type tracer struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+// hook returns the operation hook starting and ending the span of the operations of c
+func (t *tracer) hook(c *accountclient.Client) accountclient.OperationHook {
+	return func(ctx context.Context, operation string, request interface{}) (context.Context, func(interface{}, error)) {
+		accountID, organisationID := requestIDs(request)
+		ctx, span := t.tracer.Start(ctx, "accountclient."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(OperationKey.String(operation)),
+		)
+		if accountID != "" {
+			span.SetAttributes(AccountIDKey.String(accountID.String()))
+		}
+
+		return ctx, func(response interface{}, err error) {
+			defer span.End()
+			if organisationID == "" {
+				organisationID = responseOrganisationID(response)
+			}
+			if organisationID == "" {
+				// the requests and responses without organisation (e.g. DeleteAccount) are of the client one
+				organisationID = c.OrganisationID()
+			}
+			if organisationID != "" {
+				span.SetAttributes(OrganisationIDKey.String(organisationID.String()))
+			}
+			if err == nil {
+				return
+			}
+			var accErr *accountclient.AccountError
+			if errors.As(err, &accErr) && accErr.Kind != nil {
+				span.SetAttributes(ErrorKindKey.String(accErr.Kind.Error()))
+			}
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+}
+
+// intercept sends the span context of the operation with an attempt and records it as an event of the span
+func (t *tracer) intercept(inv *accountclient.Invocation, next accountclient.Handler) (*http.Response, error) {
+	r := inv.HTTPRequest
+	span := trace.SpanFromContext(r.Context())
+	t.propagator.Inject(r.Context(), propagation.HeaderCarrier(r.Header))
+	span.SetAttributes(
+		AttemptKey.Int(inv.Attempt),
+		semconv.HTTPMethodKey.String(r.Method),
+		semconv.HTTPURLKey.String(r.URL.String()),
+	)
+
+	res, err := next(inv)
+	attrs := []attribute.KeyValue{AttemptKey.Int(inv.Attempt)}
+	status := 0
+	if res != nil {
+		status = res.StatusCode
+	}
+	var accErr *accountclient.AccountError
+	if errors.As(err, &accErr) {
+		if status == 0 {
+			status = accErr.StatusCode
+		}
+		if accErr.Kind != nil {
+			attrs = append(attrs, ErrorKindKey.String(accErr.Kind.Error()))
+		}
+	}
+	if status != 0 {
+		attrs = append(attrs, semconv.HTTPStatusCodeKey.Int(status))
+		span.SetAttributes(semconv.HTTPStatusCodeKey.Int(status))
+	}
+	span.AddEvent(AttemptEvent, trace.WithAttributes(attrs...))
+	return res, err
+}
+
+// requestIDs returns the account and organisation IDs of a request, empty when it has none
+func requestIDs(request interface{}) (accountID, organisationID strfmt.UUID) {
+	switch req := request.(type) {
+	case *types.CreateAccountRequest:
+		if req.Data != nil && req.Data.ID != nil {
+			accountID = *req.Data.ID
+		}
+		if req.Data != nil && req.Data.OrganisationID != nil {
+			organisationID = *req.Data.OrganisationID
+		}
+	case *types.FetchAccountRequest:
+		accountID = req.AccountID
+	case *types.DeleteAccountRequest:
+		accountID = req.AccountID
+	case *types.AmendAccountRequest:
+		accountID = req.AccountID
+	case *types.FetchAccountEventsRequest:
+		accountID = req.AccountID
+	case *types.CreateAccountIdentificationRequest:
+		accountID = req.AccountID
+	case *types.FetchAccountIdentificationRequest:
+		accountID = req.AccountID
+	case *types.DeleteAccountIdentificationRequest:
+		accountID = req.AccountID
+	case *types.ListAccountIdentificationsRequest:
+		accountID = req.AccountID
+	case *types.ListAccountRequestsRequest:
+		organisationID = req.OrganisationID
+	}
+	return accountID, organisationID
+}
+
+// responseOrganisationID returns the organisation ID of a decoded response, empty when it has none
+func responseOrganisationID(response interface{}) strfmt.UUID {
+	switch res := response.(type) {
+	case *types.CreateAccountResponse:
+		return accountOrganisationID(res.Data)
+	case *types.FetchAccountResponse:
+		return accountOrganisationID(res.Data)
+	case *types.AmendAccountResponse:
+		return accountOrganisationID(res.Data)
+	case *types.CreateAccountIdentificationResponse:
+		if res.Data != nil {
+			return res.Data.OrganisationID
+		}
+	case *types.FetchAccountIdentificationResponse:
+		if res.Data != nil {
+			return res.Data.OrganisationID
+		}
+	case *types.AccountAmendmentResponse:
+		if res.Data != nil {
+			return res.Data.OrganisationID
+		}
+	case *types.AccountRequestResponse:
+		if res.Data != nil {
+			return res.Data.OrganisationID
+		}
+	}
+	return ""
+}
+
+// accountOrganisationID returns the organisation ID of account, empty when it has none
+func accountOrganisationID(account *models.Account) strfmt.UUID {
+	if account == nil || account.OrganisationID == nil {
+		return ""
+	}
+	return *account.OrganisationID
+}
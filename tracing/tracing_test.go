@@ -0,0 +1,193 @@
+package tracing_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/localhost418/accountclient"
+	"github.com/localhost418/accountclient/accountapitest"
+	"github.com/localhost418/accountclient/generated/models"
+	"github.com/localhost418/accountclient/tracing"
+	"github.com/localhost418/accountclient/types"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestInstrument(t *testing.T) {
+	api := accountapitest.NewServer()
+	defer api.Close()
+	var mu sync.Mutex
+	var traceparents []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		traceparents = append(traceparents, r.Header.Get("traceparent"))
+		first := len(traceparents) == 1
+		mu.Unlock()
+		if first {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		api.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	clientOrganisationID := "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c"
+	cli, err := accountclient.NewClientWithOptions(srv.URL,
+		accountclient.WithOrganisationID(clientOrganisationID),
+		accountclient.WithRetryPolicy(&accountclient.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}),
+		tracing.Instrument(&tracing.Options{TracerProvider: tp}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "reconcile")
+	accountID := strfmt.UUID("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+	organisationID := strfmt.UUID("cd27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+	unknownID := strfmt.UUID("00000000-0000-0000-0000-000000000000")
+	country := "GB"
+	_, err = cli.CreateAccountWithContext(ctx, &types.CreateAccountRequest{
+		Data: &models.Account{ID: &accountID, OrganisationID: &organisationID, Attributes: &models.AccountAttributes{Country: &country}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, err := cli.FetchAccountWithContext(ctx, &types.FetchAccountRequest{AccountID: accountID}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, err := cli.FetchAccountWithContext(ctx, &types.FetchAccountRequest{AccountID: unknownID}); err == nil {
+		t.Fatalf("expected a not found error")
+	}
+	if _, err := cli.DeleteAccountWithContext(ctx, &types.DeleteAccountRequest{AccountID: accountID, Version: 0}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	parent.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 5 {
+		t.Fatalf("wrong number of spans: want 5 got %d", len(spans))
+	}
+	tt := []struct {
+		name     string
+		status   codes.Code
+		attrs    map[attribute.Key]attribute.Value
+		attempts []int
+	}{
+		{
+			name: "accountclient.CreateAccount",
+			attrs: map[attribute.Key]attribute.Value{
+				tracing.OperationKey:      attribute.StringValue(accountclient.OperationCreateAccount),
+				tracing.AccountIDKey:      attribute.StringValue(accountID.String()),
+				tracing.OrganisationIDKey: attribute.StringValue(organisationID.String()),
+				tracing.AttemptKey:        attribute.IntValue(2),
+				"http.method":             attribute.StringValue(http.MethodPost),
+				"http.status_code":        attribute.IntValue(http.StatusCreated),
+			},
+			attempts: []int{http.StatusServiceUnavailable, http.StatusCreated},
+		},
+		{
+			name: "accountclient.FetchAccount",
+			attrs: map[attribute.Key]attribute.Value{
+				tracing.OperationKey:      attribute.StringValue(accountclient.OperationFetchAccount),
+				tracing.AccountIDKey:      attribute.StringValue(accountID.String()),
+				tracing.OrganisationIDKey: attribute.StringValue(organisationID.String()),
+				tracing.AttemptKey:        attribute.IntValue(1),
+				"http.status_code":        attribute.IntValue(http.StatusOK),
+			},
+			attempts: []int{http.StatusOK},
+		},
+		{
+			name:   "accountclient.FetchAccount",
+			status: codes.Error,
+			attrs: map[attribute.Key]attribute.Value{
+				tracing.OperationKey:      attribute.StringValue(accountclient.OperationFetchAccount),
+				tracing.AccountIDKey:      attribute.StringValue(unknownID.String()),
+				tracing.OrganisationIDKey: attribute.StringValue(clientOrganisationID),
+				tracing.ErrorKindKey:      attribute.StringValue(accountclient.ErrAPIFailure.Error()),
+				"http.status_code":        attribute.IntValue(http.StatusNotFound),
+			},
+			attempts: []int{http.StatusNotFound},
+		},
+		{
+			name: "accountclient.DeleteAccount",
+			attrs: map[attribute.Key]attribute.Value{
+				tracing.OperationKey:      attribute.StringValue(accountclient.OperationDeleteAccount),
+				tracing.AccountIDKey:      attribute.StringValue(accountID.String()),
+				tracing.OrganisationIDKey: attribute.StringValue(clientOrganisationID),
+				tracing.AttemptKey:        attribute.IntValue(1),
+				"http.method":             attribute.StringValue(http.MethodDelete),
+				"http.status_code":        attribute.IntValue(http.StatusNoContent),
+			},
+			attempts: []int{http.StatusNoContent},
+		},
+	}
+
+	request := 0
+	for i, tc := range tt {
+		span := spans[i]
+		if span.Name != tc.name || span.SpanKind != trace.SpanKindClient || span.Status.Code != tc.status {
+			t.Fatalf("wrong span %s (kind %v, status %v)", span.Name, span.SpanKind, span.Status)
+		}
+		if span.Parent.SpanID() != parent.SpanContext().SpanID() {
+			t.Fatalf("wrong parent span %s", span.Parent.SpanID())
+		}
+		attrs := map[attribute.Key]attribute.Value{}
+		for _, kv := range span.Attributes {
+			attrs[kv.Key] = kv.Value
+		}
+		for k, want := range tc.attrs {
+			if !reflect.DeepEqual(attrs[k], want) {
+				t.Fatalf("wrong attribute %s of %s: want %v got %v", k, tc.name, want.Emit(), attrs[k].Emit())
+			}
+		}
+
+		// the failed operations also have the exception event of their error
+		var events []sdktrace.Event
+		for _, e := range span.Events {
+			if e.Name == tracing.AttemptEvent {
+				events = append(events, e)
+			}
+		}
+		if len(events) != len(tc.attempts) {
+			t.Fatalf("wrong number of attempts of %s: want %d got %d", tc.name, len(tc.attempts), len(events))
+		}
+		want := "00-" + span.SpanContext.TraceID().String() + "-" + span.SpanContext.SpanID().String() + "-01"
+		for j, status := range tc.attempts {
+			event := map[attribute.Key]attribute.Value{}
+			for _, kv := range events[j].Attributes {
+				event[kv.Key] = kv.Value
+			}
+			if event[tracing.AttemptKey].AsInt64() != int64(j+1) || event["http.status_code"].AsInt64() != int64(status) {
+				t.Fatalf("wrong attempt %d of %s: %v", j+1, tc.name, events[j])
+			}
+			if traceparents[request] != want {
+				t.Fatalf("wrong traceparent: want %s got %s", want, traceparents[request])
+			}
+			request++
+		}
+	}
+}
+
+func TestInstrumentDefaults(t *testing.T) {
+	api := accountapitest.NewServer()
+	defer api.Close()
+	cli, err := accountclient.NewClientWithOptions(api.URL, tracing.Instrument(nil))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	// the global provider does not record, the operations run as usual
+	if _, err := cli.ListAccounts(&types.ListAccountsRequest{}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package types
+
+import (
+	"github.com/go-openapi/strfmt"
+	"github.com/localhost418/accountclient/generated/models"
+)
+
+// AccountAmendment represents an AccountAmendment ressource, the asynchronous change of an account through the scheme
+// (not generated since only the Account ressource is)
+type AccountAmendment struct {
+	ID             strfmt.UUID                    `json:"id"`
+	OrganisationID strfmt.UUID                    `json:"organisation_id"`
+	Type           string                         `json:"type"`
+	Version        *int64                         `json:"version,omitempty"`
+	CreatedOn      *strfmt.DateTime               `json:"created_on,omitempty"`
+	ModifiedOn     *strfmt.DateTime               `json:"modified_on,omitempty"`
+	Attributes     *AccountAmendmentAttributes    `json:"attributes"`
+	Relationships  *AccountAmendmentRelationships `json:"relationships,omitempty"`
+}
+
+// AccountAmendmentAttributes are the account fields changed by an AccountAmendment
+type AccountAmendmentAttributes struct {
+	ModifyReason               string                                              `json:"modify_reason,omitempty"`
+	Name                       []string                                            `json:"name,omitempty"`
+	OrganisationIdentification *models.AccountAttributesOrganisationIdentification `json:"organisation_identification,omitempty"`
+	PrivateIdentification      *models.AccountAttributesPrivateIdentification      `json:"private_identification,omitempty"`
+}
+
+// AccountAmendmentRelationships links an AccountAmendment to the amended account and to its submission
+type AccountAmendmentRelationships struct {
+	Account                    *AmendmentAccountRelationship    `json:"account,omitempty"`
+	AccountAmendmentSubmission *AmendmentSubmissionRelationship `json:"account_amendment_submission,omitempty"`
+}
+
+// AmendmentAccountRelationship holds the reference of the amended account (exactly one)
+type AmendmentAccountRelationship struct {
+	Data []*AmendmentAccountReference `json:"data"`
+}
+
+// AmendmentAccountReference references an account by ID (type accounts)
+type AmendmentAccountReference struct {
+	ID      strfmt.UUID `json:"id"`
+	Type    string      `json:"type,omitempty"`
+	Version *int64      `json:"version,omitempty"`
+}
+
+// AmendmentSubmissionRelationship holds the submission of an AccountAmendment (read only)
+type AmendmentSubmissionRelationship struct {
+	Data []*AccountAmendmentSubmission `json:"data,omitempty"`
+}
+
+// AccountAmendmentSubmission represents an AccountAmendmentSubmission ressource, the submission of an AccountAmendment to the scheme
+type AccountAmendmentSubmission struct {
+	ID             strfmt.UUID           `json:"id"`
+	OrganisationID strfmt.UUID           `json:"organisation_id"`
+	Type           string                `json:"type"`
+	Version        int64                 `json:"version"`
+	CreatedOn      *strfmt.DateTime      `json:"created_on,omitempty"`
+	ModifiedOn     *strfmt.DateTime      `json:"modified_on,omitempty"`
+	Attributes     *SubmissionAttributes `json:"attributes,omitempty"`
+}
@@ -0,0 +1,17 @@
+package types
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// AccountAmendmentResponse represents the API response for a POST or GET account amendment ressource request
+type AccountAmendmentResponse struct {
+	Data  *AccountAmendment             `json:"data"`
+	Links *AccountCreationResponseLinks `json:"links,omitempty"`
+}
+
+// ReadFrom implements io.ReaderFrom using JSON
+func (a *AccountAmendmentResponse) ReadFrom(r io.Reader) (int64, error) {
+	return 0, json.NewDecoder(r).Decode(a)
+}
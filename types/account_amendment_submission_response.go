@@ -0,0 +1,17 @@
+package types
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// AccountAmendmentSubmissionResponse represents the API response for a POST or GET account amendment submission ressource request
+type AccountAmendmentSubmissionResponse struct {
+	Data  *AccountAmendmentSubmission   `json:"data"`
+	Links *AccountCreationResponseLinks `json:"links,omitempty"`
+}
+
+// ReadFrom implements io.ReaderFrom using JSON
+func (a *AccountAmendmentSubmissionResponse) ReadFrom(r io.Reader) (int64, error) {
+	return 0, json.NewDecoder(r).Decode(a)
+}
@@ -0,0 +1,56 @@
+package types
+
+import (
+	"github.com/go-openapi/strfmt"
+	"github.com/localhost418/accountclient/generated/models"
+)
+
+// AccountEventStatus is the status (and description) of an account event
+type AccountEventStatus string
+
+// account event statuses
+const (
+	AccountEventPending   AccountEventStatus = "pending"
+	AccountEventFailed    AccountEventStatus = "failed"
+	AccountEventConfirmed AccountEventStatus = "confirmed"
+)
+
+// AccountRoutingStatus is the routing status of the account of an event
+type AccountRoutingStatus string
+
+// account routing statuses
+const (
+	AccountUnroutable AccountRoutingStatus = "unroutable"
+	AccountRoutable   AccountRoutingStatus = "routable"
+	AccountDeleted    AccountRoutingStatus = "deleted"
+)
+
+// AccountEvent represents an AccountEvent ressource (not generated since only the Account ressource is)
+type AccountEvent struct {
+	ID             strfmt.UUID                `json:"id"`
+	OrganisationID strfmt.UUID                `json:"organisation_id"`
+	Type           string                     `json:"type"`
+	Version        int64                      `json:"version"`
+	Attributes     *AccountEventAttributes    `json:"attributes"`
+	Relationships  *AccountEventRelationships `json:"relationships,omitempty"`
+}
+
+// AccountEventAttributes are the attributes of an AccountEvent, Reason is only present when Description is failed
+type AccountEventAttributes struct {
+	AccountID     strfmt.UUID          `json:"account_id"`
+	DateTime      strfmt.DateTime      `json:"date_time"`
+	Description   AccountEventStatus   `json:"description,omitempty"`
+	Reason        string               `json:"reason,omitempty"`
+	RoutingStatus AccountRoutingStatus `json:"routing_status"`
+	Status        AccountEventStatus   `json:"status"`
+}
+
+// AccountEventRelationships links an AccountEvent to its account
+type AccountEventRelationships struct {
+	Account *AccountEventRelationshipAccount `json:"account,omitempty"`
+}
+
+// AccountEventRelationshipAccount is the account an event relates to
+type AccountEventRelationshipAccount struct {
+	Data []*models.Account `json:"data"`
+}
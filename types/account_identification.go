@@ -0,0 +1,32 @@
+package types
+
+import "github.com/go-openapi/strfmt"
+
+// AccountIdentification represents an AccountIdentification ressource, a secondary identification attached to an account
+// (not generated since only the Account ressource is)
+type AccountIdentification struct {
+	ID             strfmt.UUID                         `json:"id"`
+	OrganisationID strfmt.UUID                         `json:"organisation_id"`
+	Type           string                              `json:"type,omitempty"`
+	Version        *int64                              `json:"version,omitempty"`
+	CreatedOn      *strfmt.DateTime                    `json:"created_on,omitempty"`
+	ModifiedOn     *strfmt.DateTime                    `json:"modified_on,omitempty"`
+	Attributes     *AccountIdentificationAttributes    `json:"attributes"`
+	Relationships  *AccountIdentificationRelationships `json:"relationships,omitempty"`
+}
+
+// AccountIdentificationAttributes are the attributes of an AccountIdentification
+type AccountIdentificationAttributes struct {
+	// SecondaryIdentification is matched exactly against the reference information of payments (1 to 35 characters)
+	SecondaryIdentification string `json:"secondary_identification"`
+}
+
+// AccountIdentificationRelationships links an AccountIdentification to its account
+type AccountIdentificationRelationships struct {
+	Account *AccountIdentificationRelationshipLinks `json:"account,omitempty"`
+}
+
+// AccountIdentificationRelationshipLinks are the links of the account of an AccountIdentification
+type AccountIdentificationRelationshipLinks struct {
+	Links *AccountCreationResponseLinks `json:"links,omitempty"`
+}
@@ -0,0 +1,73 @@
+package types
+
+import (
+	"github.com/go-openapi/strfmt"
+	"github.com/localhost418/accountclient/generated/models"
+)
+
+// AccountRequest represents an AccountRequest ressource, the request to open an account through the scheme
+// (not generated since only the Account ressource is)
+type AccountRequest struct {
+	ID             strfmt.UUID                  `json:"id"`
+	OrganisationID strfmt.UUID                  `json:"organisation_id"`
+	Type           string                       `json:"type"`
+	Version        *int64                       `json:"version,omitempty"`
+	CreatedOn      *strfmt.DateTime             `json:"created_on,omitempty"`
+	ModifiedOn     *strfmt.DateTime             `json:"modified_on,omitempty"`
+	Attributes     *AccountRequestAttributes    `json:"attributes"`
+	Relationships  *AccountRequestRelationships `json:"relationships,omitempty"`
+}
+
+// AccountRequestAttributes are the attributes of the account to open, Country, BaseCurrency and Bic are required
+type AccountRequestAttributes struct {
+	AccountNumber              string                                              `json:"account_number,omitempty"`
+	BankID                     string                                              `json:"bank_id,omitempty"`
+	BankIDCode                 string                                              `json:"bank_id_code,omitempty"`
+	BaseCurrency               string                                              `json:"base_currency"`
+	Bic                        string                                              `json:"bic"`
+	Country                    string                                              `json:"country"`
+	Iban                       string                                              `json:"iban,omitempty"`
+	Name                       []string                                            `json:"name,omitempty"`
+	OrganisationIdentification *models.AccountAttributesOrganisationIdentification `json:"organisation_identification,omitempty"`
+	PrivateIdentification      *models.AccountAttributesPrivateIdentification      `json:"private_identification,omitempty"`
+}
+
+// AccountRequestRelationships links an AccountRequest to the opened account, its master account and its submission
+type AccountRequestRelationships struct {
+	Account                  *AccountReferences                  `json:"account,omitempty"`
+	MasterAccount            *AccountReferences                  `json:"master_account,omitempty"`
+	AccountRequestSubmission *AccountRequestSubmissionReferences `json:"account_request_submission,omitempty"`
+}
+
+// AccountReferences holds references of accounts
+type AccountReferences struct {
+	Data []*AccountReference `json:"data"`
+}
+
+// AccountReference references an account by ID (type accounts)
+type AccountReference struct {
+	ID   strfmt.UUID `json:"id"`
+	Type string      `json:"type,omitempty"`
+}
+
+// AccountRequestSubmissionReferences holds the submission of an AccountRequest (read only)
+type AccountRequestSubmissionReferences struct {
+	Data []*AccountRequestSubmission `json:"data,omitempty"`
+}
+
+// AccountRequestSubmission represents an AccountRequestSubmission ressource, the submission of an AccountRequest to the scheme
+type AccountRequestSubmission struct {
+	ID             strfmt.UUID                            `json:"id"`
+	OrganisationID strfmt.UUID                            `json:"organisation_id"`
+	Type           string                                 `json:"type"`
+	Version        int64                                  `json:"version"`
+	CreatedOn      *strfmt.DateTime                       `json:"created_on,omitempty"`
+	ModifiedOn     *strfmt.DateTime                       `json:"modified_on,omitempty"`
+	Attributes     *SubmissionAttributes                  `json:"attributes,omitempty"`
+	Relationships  *AccountRequestSubmissionRelationships `json:"relationships,omitempty"`
+}
+
+// AccountRequestSubmissionRelationships links an AccountRequestSubmission to the opened account (read only)
+type AccountRequestSubmissionRelationships struct {
+	Account *AccountReferences `json:"account,omitempty"`
+}
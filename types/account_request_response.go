@@ -0,0 +1,17 @@
+package types
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// AccountRequestResponse represents the API response for a POST or GET account request ressource request
+type AccountRequestResponse struct {
+	Data  *AccountRequest               `json:"data"`
+	Links *AccountCreationResponseLinks `json:"links,omitempty"`
+}
+
+// ReadFrom implements io.ReaderFrom using JSON
+func (a *AccountRequestResponse) ReadFrom(r io.Reader) (int64, error) {
+	return 0, json.NewDecoder(r).Decode(a)
+}
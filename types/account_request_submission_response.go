@@ -0,0 +1,17 @@
+package types
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// AccountRequestSubmissionResponse represents the API response for a POST or GET account request submission ressource request
+type AccountRequestSubmissionResponse struct {
+	Data  *AccountRequestSubmission     `json:"data"`
+	Links *AccountCreationResponseLinks `json:"links,omitempty"`
+}
+
+// ReadFrom implements io.ReaderFrom using JSON
+func (a *AccountRequestSubmissionResponse) ReadFrom(r io.Reader) (int64, error) {
+	return 0, json.NewDecoder(r).Decode(a)
+}
@@ -0,0 +1,92 @@
+package types
+
+import "fmt"
+
+// AccountStatus is the status of an account (AccountAttributes.Status)
+type AccountStatus string
+
+// account statuses
+const (
+	AccountStatusPending   AccountStatus = "pending"
+	AccountStatusFailed    AccountStatus = "failed"
+	AccountStatusConfirmed AccountStatus = "confirmed"
+	AccountStatusClosed    AccountStatus = "closed"
+)
+
+// AccountStatusReason is the reason an account is closed (AccountAttributes.StatusReason), only valid with AccountStatusClosed
+type AccountStatusReason string
+
+// account status reasons
+const (
+	AccountStatusReasonUnspecified    AccountStatusReason = "unspecified"
+	AccountStatusReasonClosed         AccountStatusReason = "closed"
+	AccountStatusReasonStopped        AccountStatusReason = "stopped"
+	AccountStatusReasonCurrency       AccountStatusReason = "currency"
+	AccountStatusReasonTransferred    AccountStatusReason = "transferred"
+	AccountStatusReasonDeceased       AccountStatusReason = "deceased"
+	AccountStatusReasonBusinessReason AccountStatusReason = "business_reason"
+	AccountStatusReasonNone           AccountStatusReason = "none"
+)
+
+// accountStatusTransitions are the statuses an account can move to from each status, closed is final
+var accountStatusTransitions = map[AccountStatus][]AccountStatus{
+	AccountStatusPending:   {AccountStatusConfirmed, AccountStatusFailed, AccountStatusClosed},
+	AccountStatusFailed:    {AccountStatusPending, AccountStatusClosed},
+	AccountStatusConfirmed: {AccountStatusClosed},
+	AccountStatusClosed:    nil,
+}
+
+// Valid tells whether s is one of the account statuses
+func (s AccountStatus) Valid() bool {
+	_, ok := accountStatusTransitions[s]
+	return ok
+}
+
+// Valid tells whether r is one of the account status reasons
+func (r AccountStatusReason) Valid() bool {
+	switch r {
+	case AccountStatusReasonUnspecified, AccountStatusReasonClosed, AccountStatusReasonStopped, AccountStatusReasonCurrency,
+		AccountStatusReasonTransferred, AccountStatusReasonDeceased, AccountStatusReasonBusinessReason, AccountStatusReasonNone:
+		return true
+	}
+	return false
+}
+
+// CanTransitionTo tells whether an account with status s can move to status next (an account without status is pending),
+// keeping the same status is not a transition and is always allowed
+func (s AccountStatus) CanTransitionTo(next AccountStatus) bool {
+	if s == "" {
+		s = AccountStatusPending
+	}
+	if s == next {
+		return true
+	}
+	for _, to := range accountStatusTransitions[s] {
+		if to == next {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateStatusTransition checks an account with status from can move to status to with reason (empty when none)
+func ValidateStatusTransition(from, to AccountStatus, reason AccountStatusReason) error {
+	if from != "" && !from.Valid() {
+		return fmt.Errorf("unknown account status %q", from)
+	}
+	if !to.Valid() {
+		return fmt.Errorf("unknown account status %q", to)
+	}
+	if !from.CanTransitionTo(to) {
+		return fmt.Errorf("account status cannot change from %s to %s", from, to)
+	}
+	if reason != "" {
+		if !reason.Valid() {
+			return fmt.Errorf("unknown account status reason %q", reason)
+		}
+		if to != AccountStatusClosed {
+			return fmt.Errorf("account status reason %s requires status closed, not %s", reason, to)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package types
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/localhost418/accountclient/generated/models"
+)
+
+/*
+AmendAccountRequest contains all the parameters to PATCH an Account ressource through the account API.
+Version is the current version of the account: the API rejects the amendment if the account changed in the meantime.
+Only the attributes set are sent and changed, a nil list or pointer leaves the attribute as it is (an empty list clears it).
+*/
+type AmendAccountRequest struct {
+	AccountID  strfmt.UUID
+	Version    int
+	Attributes *models.AccountAttributes
+}
+
+// amendAccountData is the JSON:API resource sent as the PATCH body
+type amendAccountData struct {
+	ID         strfmt.UUID                `json:"id"`
+	Type       string                     `json:"type"`
+	Version    int                        `json:"version"`
+	Attributes map[string]json.RawMessage `json:"attributes"`
+}
+
+// WriteTo implements io.WriterTo using JSON, the attributes being a merge patch of the set attributes
+func (a *AmendAccountRequest) WriteTo(w io.Writer) (int64, error) {
+	attributes, err := amendedAttributes(a.Attributes)
+	if err != nil {
+		return 0, err
+	}
+	body := struct {
+		Data *amendAccountData `json:"data"`
+	}{
+		Data: &amendAccountData{
+			ID:         a.AccountID,
+			Type:       "accounts",
+			Version:    a.Version,
+			Attributes: attributes,
+		},
+	}
+	return 0, json.NewEncoder(w).Encode(body)
+}
+
+// amendedAttributes drops the unset attributes, which the generated model encodes as null when they are not omitempty
+func amendedAttributes(attributes *models.AccountAttributes) (map[string]json.RawMessage, error) {
+	if attributes == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(attributes)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	for name, value := range fields {
+		if string(value) == "null" {
+			delete(fields, name)
+		}
+	}
+	return fields, nil
+}
@@ -0,0 +1,19 @@
+package types
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/localhost418/accountclient/generated/models"
+)
+
+// AmendAccountResponse represents the API response for a PATCH account ressource request
+type AmendAccountResponse struct {
+	Data  *models.Account               `json:"data,omitempty"`
+	Links *AccountCreationResponseLinks `json:"links,omitempty"`
+}
+
+// ReadFrom implements io.ReaderFrom using JSON
+func (c *AmendAccountResponse) ReadFrom(r io.Reader) (int64, error) {
+	return 0, json.NewDecoder(r).Decode(c)
+}
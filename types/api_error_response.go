@@ -0,0 +1,50 @@
+package types
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// APIErrorResponse represents the API error body (ApiError) returned on non 2xx responses
+type APIErrorResponse struct {
+
+	// Example: Fail to process your request
+	ErrorMessage string `json:"error_message,omitempty"`
+
+	// Example: 2df52024-8b8d-4dc1-b713-1f6d8aa6ae48
+	ErrorCode string `json:"error_code,omitempty"`
+
+	// JSON:API error objects (returned by some gateways instead of ApiError)
+	Errors []*APIErrorObject `json:"errors,omitempty"`
+}
+
+// APIErrorObject represents a JSON:API error object
+type APIErrorObject struct {
+	Status string `json:"status,omitempty"`
+	Code   string `json:"code,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// ReadFrom implements io.ReaderFrom using JSON
+func (c *APIErrorResponse) ReadFrom(r io.Reader) (int64, error) {
+	return 0, json.NewDecoder(r).Decode(c)
+}
+
+// Message returns the error message and code, from ApiError or else from the first JSON:API error object
+func (c *APIErrorResponse) Message() (string, string) {
+	if c.ErrorMessage != "" || c.ErrorCode != "" {
+		return c.ErrorMessage, c.ErrorCode
+	}
+	for _, e := range c.Errors {
+		if e == nil {
+			continue
+		}
+		msg := e.Detail
+		if msg == "" {
+			msg = e.Title
+		}
+		return msg, e.Code
+	}
+	return "", ""
+}
@@ -0,0 +1,16 @@
+package types
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// CreateAccountAmendmentRequest contains all the parameters to POST an AccountAmendment ressource through the account API
+type CreateAccountAmendmentRequest struct {
+	Data *AccountAmendment `json:"data"`
+}
+
+// WriteTo implements io.WriterTo using JSON
+func (c *CreateAccountAmendmentRequest) WriteTo(w io.Writer) (int64, error) {
+	return 0, json.NewEncoder(w).Encode(c)
+}
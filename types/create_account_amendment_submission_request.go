@@ -0,0 +1,19 @@
+package types
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/go-openapi/strfmt"
+)
+
+// CreateAccountAmendmentSubmissionRequest contains all the parameters to POST the submission of an AccountAmendment through the account API
+type CreateAccountAmendmentSubmissionRequest struct {
+	AmendmentID strfmt.UUID                 `json:"-"`
+	Data        *AccountAmendmentSubmission `json:"data"`
+}
+
+// WriteTo implements io.WriterTo using JSON
+func (c *CreateAccountAmendmentSubmissionRequest) WriteTo(w io.Writer) (int64, error) {
+	return 0, json.NewEncoder(w).Encode(c)
+}
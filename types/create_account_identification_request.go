@@ -0,0 +1,19 @@
+package types
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/go-openapi/strfmt"
+)
+
+// CreateAccountIdentificationRequest contains all the parameters to POST an AccountIdentification ressource through the account API
+type CreateAccountIdentificationRequest struct {
+	AccountID strfmt.UUID            `json:"-"`
+	Data      *AccountIdentification `json:"data"`
+}
+
+// WriteTo implements io.WriterTo using JSON
+func (c *CreateAccountIdentificationRequest) WriteTo(w io.Writer) (int64, error) {
+	return 0, json.NewEncoder(w).Encode(c)
+}
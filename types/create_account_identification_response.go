@@ -0,0 +1,17 @@
+package types
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// CreateAccountIdentificationResponse represents the API response for a POST account identification ressource request
+type CreateAccountIdentificationResponse struct {
+	Data  *AccountIdentification        `json:"data"`
+	Links *AccountCreationResponseLinks `json:"links,omitempty"`
+}
+
+// ReadFrom implements io.ReaderFrom using JSON
+func (c *CreateAccountIdentificationResponse) ReadFrom(r io.Reader) (int64, error) {
+	return 0, json.NewDecoder(r).Decode(c)
+}
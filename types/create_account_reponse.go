@@ -7,10 +7,12 @@ import (
 	"github.com/localhost418/accountclient/generated/models"
 )
 
-// CreateAccountResponse represents the API response for a POST account ressource request
+// CreateAccountResponse represents the API response for a POST account ressource request,
+// Existing tells the account was not created but already existed with the requested attributes (see CreateAccountIdempotent)
 type CreateAccountResponse struct {
-	Data  *models.Account               `json:"data"`
-	Links *AccountCreationResponseLinks `json:"links,omitempty"`
+	Data     *models.Account               `json:"data"`
+	Links    *AccountCreationResponseLinks `json:"links,omitempty"`
+	Existing bool                          `json:"-"`
 }
 
 /*
@@ -7,9 +7,25 @@ import (
 	"github.com/localhost418/accountclient/generated/models"
 )
 
+// IbanMode tells how the client handles the iban of a CreateAccountRequest before sending it
+type IbanMode int
+
+const (
+	// IbanAsIs sends the iban as provided
+	IbanAsIs IbanMode = iota
+	// IbanFill derives the iban from the country, bank_id, account_number and bic when not provided, and checks it otherwise
+	IbanFill
+	// IbanCheck checks the provided iban and that it matches the other fields when the iban can be derived from them,
+	// the provided iban of IbanFill and IbanCheck is sent normalized (no spaces, upper case)
+	IbanCheck
+)
+
 // CreateAccountRequest contains all the parameters to POST an Account ressource through the account API
 type CreateAccountRequest struct {
 	Data *models.Account `json:"data"`
+
+	// Iban is not sent, it is the handling of Data.Attributes.Iban by the client
+	Iban IbanMode `json:"-"`
 }
 
 // WriteTo implements io.WriterTo using JSON
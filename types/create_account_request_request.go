@@ -0,0 +1,16 @@
+package types
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// CreateAccountRequestRequest contains all the parameters to POST an AccountRequest ressource through the account API
+type CreateAccountRequestRequest struct {
+	Data *AccountRequest `json:"data"`
+}
+
+// WriteTo implements io.WriterTo using JSON
+func (c *CreateAccountRequestRequest) WriteTo(w io.Writer) (int64, error) {
+	return 0, json.NewEncoder(w).Encode(c)
+}
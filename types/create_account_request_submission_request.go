@@ -0,0 +1,19 @@
+package types
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/go-openapi/strfmt"
+)
+
+// CreateAccountRequestSubmissionRequest contains all the parameters to POST the submission of an AccountRequest through the account API
+type CreateAccountRequestSubmissionRequest struct {
+	AccountRequestID strfmt.UUID               `json:"-"`
+	Data             *AccountRequestSubmission `json:"data"`
+}
+
+// WriteTo implements io.WriterTo using JSON
+func (c *CreateAccountRequestSubmissionRequest) WriteTo(w io.Writer) (int64, error) {
+	return 0, json.NewEncoder(w).Encode(c)
+}
@@ -0,0 +1,10 @@
+package types
+
+import "github.com/go-openapi/strfmt"
+
+// DeleteAccountIdentificationRequest contains all the parameters to DELETE an AccountIdentification ressource through the account API
+type DeleteAccountIdentificationRequest struct {
+	AccountID        strfmt.UUID
+	IdentificationID strfmt.UUID
+	Version          int
+}
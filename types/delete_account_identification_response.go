@@ -0,0 +1,4 @@
+package types
+
+// DeleteAccountIdentificationResponse represents the API response for a DELETE account identification ressource request (empty response)
+type DeleteAccountIdentificationResponse struct{}
@@ -0,0 +1,8 @@
+package types
+
+import "github.com/go-openapi/strfmt"
+
+// FetchAccountAmendmentRequest contains all the parameters to GET an AccountAmendment ressource through the account API
+type FetchAccountAmendmentRequest struct {
+	AmendmentID strfmt.UUID
+}
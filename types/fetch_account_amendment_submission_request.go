@@ -0,0 +1,9 @@
+package types
+
+import "github.com/go-openapi/strfmt"
+
+// FetchAccountAmendmentSubmissionRequest contains all the parameters to GET the submission of an AccountAmendment through the account API
+type FetchAccountAmendmentSubmissionRequest struct {
+	AmendmentID  strfmt.UUID
+	SubmissionID strfmt.UUID
+}
@@ -0,0 +1,31 @@
+package types
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/go-openapi/strfmt"
+)
+
+// FetchAccountEventsRequest contains all the parameters to GET the events of an Account ressource through the account API
+type FetchAccountEventsRequest struct {
+	AccountID strfmt.UUID
+
+	// Which page to select (first page when zero)
+	PageNumber int
+
+	// Number of items to select (API default when zero, at most MaxPageSize)
+	PageSize int
+}
+
+// Query builds the url query parameters (page[...]) of the request
+func (f *FetchAccountEventsRequest) Query() url.Values {
+	q := url.Values{}
+	if f.PageNumber > 0 {
+		q.Set("page[number]", strconv.Itoa(f.PageNumber))
+	}
+	if f.PageSize > 0 {
+		q.Set("page[size]", strconv.Itoa(f.PageSize))
+	}
+	return q
+}
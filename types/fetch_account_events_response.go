@@ -0,0 +1,17 @@
+package types
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// FetchAccountEventsResponse represents the API response for a GET account events request (AccountEventListResponse)
+type FetchAccountEventsResponse struct {
+	Data  []*AccountEvent               `json:"data"`
+	Links *AccountCreationResponseLinks `json:"links,omitempty"`
+}
+
+// ReadFrom implements io.ReaderFrom using JSON
+func (f *FetchAccountEventsResponse) ReadFrom(r io.Reader) (int64, error) {
+	return 0, json.NewDecoder(r).Decode(f)
+}
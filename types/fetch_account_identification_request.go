@@ -0,0 +1,9 @@
+package types
+
+import "github.com/go-openapi/strfmt"
+
+// FetchAccountIdentificationRequest contains all the parameters to GET an AccountIdentification ressource through the account API
+type FetchAccountIdentificationRequest struct {
+	AccountID        strfmt.UUID
+	IdentificationID strfmt.UUID
+}
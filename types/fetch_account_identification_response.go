@@ -0,0 +1,17 @@
+package types
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// FetchAccountIdentificationResponse represents the API response for a GET account identification ressource request
+type FetchAccountIdentificationResponse struct {
+	Data  *AccountIdentification        `json:"data,omitempty"`
+	Links *AccountCreationResponseLinks `json:"links,omitempty"`
+}
+
+// ReadFrom implements io.ReaderFrom using JSON
+func (c *FetchAccountIdentificationResponse) ReadFrom(r io.Reader) (int64, error) {
+	return 0, json.NewDecoder(r).Decode(c)
+}
@@ -0,0 +1,8 @@
+package types
+
+import "github.com/go-openapi/strfmt"
+
+// FetchAccountRequestRequest contains all the parameters to GET an AccountRequest ressource through the account API
+type FetchAccountRequestRequest struct {
+	AccountRequestID strfmt.UUID
+}
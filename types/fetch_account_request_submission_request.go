@@ -0,0 +1,9 @@
+package types
+
+import "github.com/go-openapi/strfmt"
+
+// FetchAccountRequestSubmissionRequest contains all the parameters to GET the submission of an AccountRequest through the account API
+type FetchAccountRequestSubmissionRequest struct {
+	AccountRequestID strfmt.UUID
+	SubmissionID     strfmt.UUID
+}
@@ -0,0 +1,42 @@
+package types
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/go-openapi/strfmt"
+)
+
+// ListAccountIdentificationsRequest contains all the parameters to GET the AccountIdentification ressources of an account through the account API
+type ListAccountIdentificationsRequest struct {
+	AccountID strfmt.UUID
+
+	// Which page to select (first page when zero)
+	PageNumber int
+
+	// Number of items to select (API default when zero, at most MaxPageSize)
+	PageSize int
+
+	// Filters (each filter matches any of its values)
+	OrganisationIDs          []strfmt.UUID
+	SecondaryIdentifications []string
+}
+
+// Query builds the url query parameters (page[...] and csv filter[...]) of the request
+func (l *ListAccountIdentificationsRequest) Query() url.Values {
+	q := url.Values{}
+	if l.PageNumber > 0 {
+		q.Set("page[number]", strconv.Itoa(l.PageNumber))
+	}
+	if l.PageSize > 0 {
+		q.Set("page[size]", strconv.Itoa(l.PageSize))
+	}
+
+	organisationIDs := make([]string, 0, len(l.OrganisationIDs))
+	for _, id := range l.OrganisationIDs {
+		organisationIDs = append(organisationIDs, id.String())
+	}
+	addFilter(q, "organisation_id", organisationIDs)
+	addFilter(q, "secondary_identification", l.SecondaryIdentifications)
+	return q
+}
@@ -0,0 +1,17 @@
+package types
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// ListAccountIdentificationsResponse represents the API response for a GET account identifications list request (AccountIdentificationListResponse)
+type ListAccountIdentificationsResponse struct {
+	Data  []*AccountIdentification      `json:"data"`
+	Links *AccountCreationResponseLinks `json:"links,omitempty"`
+}
+
+// ReadFrom implements io.ReaderFrom using JSON
+func (l *ListAccountIdentificationsResponse) ReadFrom(r io.Reader) (int64, error) {
+	return 0, json.NewDecoder(r).Decode(l)
+}
@@ -0,0 +1,51 @@
+package types
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/go-openapi/strfmt"
+)
+
+// ListAccountRequestsRequest contains all the parameters to GET a list of AccountRequest ressources through the account API
+type ListAccountRequestsRequest struct {
+	// Which page to select (first page when zero)
+	PageNumber int
+
+	// Number of items to select (API default when zero, at most MaxPageSize)
+	PageSize int
+
+	// Filters (single values, ignored when empty)
+	OrganisationID   strfmt.UUID
+	Bic              string
+	BankID           string
+	Iban             string
+	AccountNumber    string
+	SubmissionStatus SubmissionStatus
+}
+
+// Query builds the url query parameters (page[...] and filter[...]) of the request
+func (l *ListAccountRequestsRequest) Query() url.Values {
+	q := url.Values{}
+	if l.PageNumber > 0 {
+		q.Set("page[number]", strconv.Itoa(l.PageNumber))
+	}
+	if l.PageSize > 0 {
+		q.Set("page[size]", strconv.Itoa(l.PageSize))
+	}
+
+	filters := []struct{ name, value string }{
+		{"organisation_id", l.OrganisationID.String()},
+		{"bic", l.Bic},
+		{"bank_id", l.BankID},
+		{"iban", l.Iban},
+		{"account_number", l.AccountNumber},
+		{"submission.status", string(l.SubmissionStatus)},
+	}
+	for _, f := range filters {
+		if f.value != "" {
+			q.Set("filter["+f.name+"]", f.value)
+		}
+	}
+	return q
+}
@@ -0,0 +1,17 @@
+package types
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// ListAccountRequestsResponse represents the API response for a GET account requests list request (AccountRequestListResponse)
+type ListAccountRequestsResponse struct {
+	Data  []*AccountRequest             `json:"data"`
+	Links *AccountCreationResponseLinks `json:"links,omitempty"`
+}
+
+// ReadFrom implements io.ReaderFrom using JSON
+func (l *ListAccountRequestsResponse) ReadFrom(r io.Reader) (int64, error) {
+	return 0, json.NewDecoder(r).Decode(l)
+}
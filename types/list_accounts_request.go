@@ -0,0 +1,62 @@
+package types
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-openapi/strfmt"
+)
+
+// MaxPageSize is the maximum number of items the account API returns in a single page
+const MaxPageSize = 1000
+
+// ListAccountsRequest contains all the parameters to GET a list of Account ressources through the account API
+type ListAccountsRequest struct {
+	// Which page to select (first page when zero)
+	PageNumber int
+
+	// Number of items to select (API default when zero, at most MaxPageSize)
+	PageSize int
+
+	// Filters (each filter matches any of its values)
+	OrganisationIDs []strfmt.UUID
+	BankIDCodes     []string
+	BankIDs         []string
+	AccountNumbers  []string
+	Countries       []string
+	CustomerIDs     []string
+	Ibans           []string
+}
+
+// Query builds the url query parameters (page[...] and csv filter[...]) of the request
+func (l *ListAccountsRequest) Query() url.Values {
+	q := url.Values{}
+	if l.PageNumber > 0 {
+		q.Set("page[number]", strconv.Itoa(l.PageNumber))
+	}
+	if l.PageSize > 0 {
+		q.Set("page[size]", strconv.Itoa(l.PageSize))
+	}
+
+	organisationIDs := make([]string, 0, len(l.OrganisationIDs))
+	for _, id := range l.OrganisationIDs {
+		organisationIDs = append(organisationIDs, id.String())
+	}
+	addFilter(q, "organisation_id", organisationIDs)
+	addFilter(q, "bank_id_code", l.BankIDCodes)
+	addFilter(q, "bank_id", l.BankIDs)
+	addFilter(q, "account_number", l.AccountNumbers)
+	addFilter(q, "country", l.Countries)
+	addFilter(q, "customer_id", l.CustomerIDs)
+	addFilter(q, "iban", l.Ibans)
+	return q
+}
+
+// addFilter adds a csv filter[name] parameter to q when values is not empty
+func addFilter(q url.Values, name string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+	q.Set("filter["+name+"]", strings.Join(values, ","))
+}
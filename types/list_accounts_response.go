@@ -0,0 +1,19 @@
+package types
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/localhost418/accountclient/generated/models"
+)
+
+// ListAccountsResponse represents the API response for a GET account ressources list request (AccountDetailsListResponse)
+type ListAccountsResponse struct {
+	Data  []*models.Account             `json:"data"`
+	Links *AccountCreationResponseLinks `json:"links,omitempty"`
+}
+
+// ReadFrom implements io.ReaderFrom using JSON
+func (c *ListAccountsResponse) ReadFrom(r io.Reader) (int64, error) {
+	return 0, json.NewDecoder(r).Decode(c)
+}
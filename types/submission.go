@@ -0,0 +1,29 @@
+package types
+
+import "github.com/go-openapi/strfmt"
+
+// SubmissionStatus is the status of the submission of an asynchronous request to the scheme
+type SubmissionStatus string
+
+// submission statuses, in the order a submission goes through them
+const (
+	SubmissionAccepted          SubmissionStatus = "accepted"
+	SubmissionValidationPending SubmissionStatus = "validation_pending"
+	SubmissionValidationPassed  SubmissionStatus = "validation_passed"
+	SubmissionReleasedToGateway SubmissionStatus = "released_to_gateway"
+	SubmissionSubmitted         SubmissionStatus = "submitted"
+	SubmissionDeliveryConfirmed SubmissionStatus = "delivery_confirmed"
+	SubmissionDeliveryFailed    SubmissionStatus = "delivery_failed"
+)
+
+// Terminal tells whether the submission will not change status anymore
+func (s SubmissionStatus) Terminal() bool {
+	return s == SubmissionDeliveryConfirmed || s == SubmissionDeliveryFailed
+}
+
+// SubmissionAttributes are the attributes of a submission, StatusReason describes the status (e.g. why the delivery failed)
+type SubmissionAttributes struct {
+	Status             SubmissionStatus `json:"status,omitempty"`
+	StatusReason       string           `json:"status_reason,omitempty"`
+	SubmissionDatetime *strfmt.DateTime `json:"submission_datetime,omitempty"`
+}
@@ -0,0 +1,43 @@
+package accountclient
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/go-openapi/strfmt"
+)
+
+// newUUID generates a random (version 4) UUID for the ressources created by the client
+func newUUID() (strfmt.UUID, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return formatUUID(b), nil
+}
+
+// formatUUID formats b in the 8-4-4-4-12 hexadecimal form
+func formatUUID(b [16]byte) strfmt.UUID {
+	return strfmt.UUID(fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]))
+}
+
+// newNameUUID generates the name based (version 5, SHA-1) UUID of name in namespace
+func newNameUUID(namespace strfmt.UUID, name string) (strfmt.UUID, error) {
+	ns, err := hex.DecodeString(strings.Replace(namespace.String(), "-", "", 4))
+	if err != nil || len(ns) != 16 {
+		return "", fmt.Errorf("invalid namespace uuid %q", namespace)
+	}
+	h := sha1.New()
+	_, _ = h.Write(ns)
+	_, _ = h.Write([]byte(name))
+	var b [16]byte
+	copy(b[:], h.Sum(nil))
+	b[6] = b[6]&0x0f | 0x50
+	b[8] = b[8]&0x3f | 0x80
+	return formatUUID(b), nil
+}
@@ -0,0 +1,177 @@
+package accountclient
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+
+	oaerrors "github.com/go-openapi/errors"
+	"github.com/go-openapi/strfmt"
+	"github.com/localhost418/accountclient/countryrules"
+	"github.com/localhost418/accountclient/iban"
+	"github.com/localhost418/accountclient/types"
+)
+
+// Violation is a validation failure of a request field
+type Violation struct {
+	// Field is the JSON path of the field in the request body, e.g. "data.attributes.bic"
+	Field   string
+	Message string
+}
+
+// ValidateCreateAccount checks req as CreateAccount does with WithValidation (and the client country rules, if any), without sending it
+func (c *Client) ValidateCreateAccount(req *types.CreateAccountRequest) error {
+	const method = http.MethodPost
+	endpoint := c.accountsPath()
+	if req == nil {
+		return NewAccountError(ErrNoRequest, method, endpoint, 0, nil)
+	}
+	req, violations := applyIban(c.withDefaultOrganisation(req))
+	if len(violations) == 0 {
+		violations = validateCreate(req, c.organisationID, c.countryRules)
+	}
+	if len(violations) > 0 {
+		return newValidationError(method, endpoint, violations)
+	}
+	return nil
+}
+
+// validateCreate runs the generated model validators, the ID/organisation checks and the country rules (if any) on a create request
+func validateCreate(req *types.CreateAccountRequest, organisationID strfmt.UUID, rules *countryrules.Registry) []Violation {
+	data := req.Data
+	if data == nil {
+		return []Violation{{Field: "data", Message: "data is required"}}
+	}
+
+	violations := flattenValidation("data", data.Validate(strfmt.Default), false)
+	if data.Type != "" && data.Type != "accounts" {
+		violations = append(violations, Violation{Field: "data.type", Message: "type must be accounts"})
+	}
+	if organisationID != "" && data.OrganisationID != nil && *data.OrganisationID != organisationID {
+		violations = append(violations, Violation{Field: "data.organisation_id", Message: "organisation_id does not match the client organisation " + organisationID.String()})
+	}
+	if rules != nil {
+		for _, fe := range rules.Validate(data.Attributes) {
+			violations = append(violations, Violation{Field: "data.attributes." + fe.Field, Message: fe.Field + " " + fe.Message + " in " + *data.Attributes.Country})
+		}
+	}
+	return sortViolations(violations)
+}
+
+// validateAmend checks the account ID and runs the generated attributes validators on an amend request,
+// required attributes are not checked as an amend only sends the attributes to change
+func validateAmend(req *types.AmendAccountRequest) []Violation {
+	var violations []Violation
+	if !strfmt.IsUUID(req.AccountID.String()) {
+		violations = append(violations, Violation{Field: "data.id", Message: "id must be a uuid"})
+	}
+	if req.Version < 0 {
+		violations = append(violations, Violation{Field: "data.version", Message: "version must be positive"})
+	}
+	if req.Attributes == nil {
+		violations = append(violations, Violation{Field: "data.attributes", Message: "attributes is required"})
+	} else {
+		violations = append(violations, flattenValidation("data", req.Attributes.Validate(strfmt.Default), true)...)
+	}
+	return sortViolations(violations)
+}
+
+// applyIban fills or checks the iban of req according to req.Iban, the returned request is a copy when the iban is filled or normalized
+func applyIban(req *types.CreateAccountRequest) (*types.CreateAccountRequest, []Violation) {
+	if req.Iban == types.IbanAsIs || req.Data == nil || req.Data.Attributes == nil || req.Data.Attributes.Country == nil {
+		return req, nil
+	}
+	attrs := req.Data.Attributes
+	derived, deriveErr := iban.Derive(*attrs.Country, attrs.BankID, attrs.AccountNumber, attrs.Bic)
+
+	if attrs.Iban == "" {
+		if req.Iban == types.IbanCheck {
+			return req, nil
+		}
+		if errors.Is(deriveErr, iban.ErrUnsupportedCountry) {
+			// the API generates the iban when it can, there is nothing to fill in the other countries
+			return req, nil
+		}
+		if deriveErr != nil {
+			return req, []Violation{{Field: "data.attributes.iban", Message: deriveErr.Error()}}
+		}
+		return withIban(req, derived), nil
+	}
+
+	given := iban.Normalize(attrs.Iban)
+	if err := iban.Validate(given); err != nil {
+		return req, []Violation{{Field: "data.attributes.iban", Message: err.Error()}}
+	}
+	if given[:2] != *attrs.Country {
+		return req, []Violation{{Field: "data.attributes.iban", Message: "iban country does not match country " + *attrs.Country}}
+	}
+	if deriveErr == nil && derived != given {
+		return req, []Violation{{Field: "data.attributes.iban", Message: "iban does not match bank_id, account_number and bic (expected " + derived + ")"}}
+	}
+	if given != attrs.Iban {
+		// the API expects the electronic format, without spaces
+		return withIban(req, given), nil
+	}
+	return req, nil
+}
+
+// withIban returns a copy of req with the iban set, so the caller request is left untouched
+func withIban(req *types.CreateAccountRequest, value string) *types.CreateAccountRequest {
+	attrs := *req.Data.Attributes
+	attrs.Iban = value
+	data := *req.Data
+	data.Attributes = &attrs
+	copied := *req
+	copied.Data = &data
+	return &copied
+}
+
+// flattenValidation converts the (possibly composite) error of a generated validator into violations of fields under prefix
+// (the generated validators already name nested fields from the account, e.g. "attributes.bic"), skipping required fields failures if skipRequired
+func flattenValidation(prefix string, err error, skipRequired bool) []Violation {
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case *oaerrors.CompositeError:
+		var violations []Violation
+		for _, nested := range e.Errors {
+			violations = append(violations, flattenValidation(prefix, nested, skipRequired)...)
+		}
+		return violations
+	case *oaerrors.Validation:
+		if skipRequired && e.Code() == oaerrors.RequiredFailCode {
+			return nil
+		}
+		field := prefix
+		if e.Name != "" {
+			field = prefix + "." + e.Name
+		}
+		return []Violation{{Field: field, Message: e.Error()}}
+	}
+	return []Violation{{Field: prefix, Message: err.Error()}}
+}
+
+// sortViolations sorts violations by field, keeping the order of the violations of a same field
+func sortViolations(violations []Violation) []Violation {
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].Field < violations[j].Field
+	})
+	return violations
+}
+
+// formatViolations joins violations for an error message
+func formatViolations(violations []Violation) string {
+	msgs := make([]string, 0, len(violations))
+	for _, v := range violations {
+		msgs = append(msgs, v.Field+": "+v.Message)
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// newValidationError makes an AccountError of kind ErrValidation for violations
+func newValidationError(method, endpoint string, violations []Violation) *AccountError {
+	accErr := NewAccountError(ErrValidation, method, endpoint, 0, nil)
+	accErr.Violations = violations
+	return accErr
+}
@@ -0,0 +1,291 @@
+package accountclient_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/localhost418/accountclient"
+	"github.com/localhost418/accountclient/generated/models"
+	"github.com/localhost418/accountclient/types"
+)
+
+func TestClientValidation(t *testing.T) {
+	accountID := strfmt.UUID("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+	organisationID := strfmt.UUID("eb0bd6f5-c3f5-44b2-b677-acd23cdde73c")
+	otherOrganisationID := strfmt.UUID("0d27e265-9605-4b4b-a0e5-3003ea9cc4dd")
+	country := "GB"
+	badCountry := "gb"
+	tt := []struct {
+		name       string
+		req        *types.CreateAccountRequest
+		violations []string
+	}{
+		{
+			name:       "no data",
+			req:        &types.CreateAccountRequest{},
+			violations: []string{"data"},
+		},
+		{
+			name: "missing id and attributes",
+			req: &types.CreateAccountRequest{Data: &models.Account{
+				OrganisationID: &organisationID,
+			}},
+			violations: []string{"data.attributes", "data.id"},
+		},
+		{
+			name: "invalid attributes",
+			req: &types.CreateAccountRequest{Data: &models.Account{
+				ID:             &accountID,
+				OrganisationID: &organisationID,
+				Type:           "accounts",
+				Attributes:     &models.AccountAttributes{Country: &badCountry, Bic: "NWBK", BankIDCode: "gbdsc"},
+			}},
+			violations: []string{"data.attributes.bank_id_code", "data.attributes.bic", "data.attributes.country"},
+		},
+		{
+			name: "wrong type",
+			req: &types.CreateAccountRequest{Data: &models.Account{
+				ID:             &accountID,
+				OrganisationID: &organisationID,
+				Type:           "payments",
+				Attributes:     &models.AccountAttributes{Country: &country},
+			}},
+			violations: []string{"data.type"},
+		},
+		{
+			name: "other organisation",
+			req: &types.CreateAccountRequest{Data: &models.Account{
+				ID:             &accountID,
+				OrganisationID: &otherOrganisationID,
+				Attributes:     &models.AccountAttributes{Country: &country},
+			}},
+			violations: []string{"data.organisation_id"},
+		},
+		{
+			name: "valid",
+			req: &types.CreateAccountRequest{Data: &models.Account{
+				ID:         &accountID,
+				Type:       "accounts",
+				Attributes: &models.AccountAttributes{Country: &country},
+			}},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				w.WriteHeader(http.StatusCreated)
+				_, _ = w.Write([]byte(`{"data":{}}`))
+			}))
+			defer server.Close()
+
+			cli, err := accountclient.NewClientWithOptions(server.URL,
+				accountclient.WithOrganisationID(organisationID.String()),
+				accountclient.WithValidation(),
+			)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			_, err = cli.CreateAccount(tc.req)
+			if len(tc.violations) == 0 {
+				if err != nil || calls != 1 {
+					t.Fatalf("unexpected error %v (%d calls)", err, calls)
+				}
+				return
+			}
+			if !errors.Is(err, accountclient.ErrValidation) {
+				t.Fatalf("unexpected error %v ; expected %v", err, accountclient.ErrValidation)
+			}
+			if calls != 0 {
+				t.Fatalf("invalid request was sent")
+			}
+			var accErr *accountclient.AccountError
+			if !errors.As(err, &accErr) {
+				t.Fatalf("errors.As(%v) is false", err)
+			}
+			var fields []string
+			for _, v := range accErr.Violations {
+				fields = append(fields, v.Field)
+				if !strings.Contains(err.Error(), v.Field+": "+v.Message) {
+					t.Fatalf("violation %v missing from error message %s", v, err)
+				}
+			}
+			if !reflect.DeepEqual(fields, tc.violations) {
+				t.Fatalf("wrong violations: want %v got %v", tc.violations, fields)
+			}
+		})
+	}
+}
+
+func TestClientCountryRules(t *testing.T) {
+	accountID := strfmt.UUID("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+	organisationID := strfmt.UUID("eb0bd6f5-c3f5-44b2-b677-acd23cdde73c")
+	country := "GB"
+
+	cli, err := accountclient.NewClientWithOptions("http://localhost:8080",
+		accountclient.WithOrganisationID(organisationID.String()),
+		accountclient.WithCountryRules(nil),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	_, err = cli.CreateAccount(&types.CreateAccountRequest{Data: &models.Account{
+		ID:         &accountID,
+		Attributes: &models.AccountAttributes{Country: &country, BankID: "40030", BankIDCode: "GBDSC", Bic: "NWBKGB22"},
+	}})
+	var accErr *accountclient.AccountError
+	if !errors.As(err, &accErr) || !errors.Is(err, accountclient.ErrValidation) {
+		t.Fatalf("unexpected error %v ; expected %v", err, accountclient.ErrValidation)
+	}
+	want := []accountclient.Violation{{Field: "data.attributes.bank_id", Message: "bank_id must be 6 digits in GB"}}
+	if !reflect.DeepEqual(accErr.Violations, want) {
+		t.Fatalf("wrong violations: want %v got %v", want, accErr.Violations)
+	}
+
+	// same checks without sending the request
+	err = cli.ValidateCreateAccount(&types.CreateAccountRequest{Data: &models.Account{
+		ID:         &accountID,
+		Attributes: &models.AccountAttributes{Country: &country, BankID: "40030", BankIDCode: "GBDSC", Bic: "NWBKGB22"},
+	}})
+	if !errors.As(err, &accErr) || !reflect.DeepEqual(accErr.Violations, want) {
+		t.Fatalf("unexpected error %v ; expected violations %v", err, want)
+	}
+	err = cli.ValidateCreateAccount(&types.CreateAccountRequest{Data: &models.Account{
+		ID:         &accountID,
+		Attributes: &models.AccountAttributes{Country: &country, BankID: "400300", BankIDCode: "GBDSC", Bic: "NWBKGB22"},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if cli.OrganisationID() != organisationID {
+		t.Fatalf("wrong organisation id: want %s got %s", organisationID, cli.OrganisationID())
+	}
+}
+
+func TestClientAmendValidation(t *testing.T) {
+	badBic := "nwbk"
+	tt := []struct {
+		name       string
+		req        *types.AmendAccountRequest
+		violations []string
+	}{
+		{
+			name:       "invalid id and no attributes",
+			req:        &types.AmendAccountRequest{AccountID: "account", Version: -1},
+			violations: []string{"data.attributes", "data.id", "data.version"},
+		},
+		{
+			name: "invalid attributes",
+			req: &types.AmendAccountRequest{
+				AccountID:  "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc",
+				Attributes: &models.AccountAttributes{Bic: badBic},
+			},
+			violations: []string{"data.attributes.bic"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			cli, err := accountclient.NewClientWithOptions("http://localhost:8080", accountclient.WithValidation())
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			_, err = cli.AmendAccount(tc.req)
+			var accErr *accountclient.AccountError
+			if !errors.As(err, &accErr) || !errors.Is(err, accountclient.ErrValidation) {
+				t.Fatalf("unexpected error %v ; expected %v", err, accountclient.ErrValidation)
+			}
+			var fields []string
+			for _, v := range accErr.Violations {
+				fields = append(fields, v.Field)
+			}
+			if !reflect.DeepEqual(fields, tc.violations) {
+				t.Fatalf("wrong violations: want %v got %v", tc.violations, fields)
+			}
+		})
+	}
+}
+
+func TestClientIban(t *testing.T) {
+	accountID := strfmt.UUID("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+	organisationID := strfmt.UUID("eb0bd6f5-c3f5-44b2-b677-acd23cdde73c")
+	tt := []struct {
+		name    string
+		country string
+		iban    string
+		mode    types.IbanMode
+		sent    string
+		err     error
+	}{
+		{name: "fill", country: "GB", mode: types.IbanFill, sent: "GB29NWBK60161331926819"},
+		{name: "fill unsupported country", country: "US", mode: types.IbanFill},
+		{name: "fill checks given iban", country: "GB", iban: "GB29NWBK60161331926818", mode: types.IbanFill, err: accountclient.ErrValidation},
+		{name: "check", country: "GB", iban: "GB29 NWBK 6016 1331 9268 19", mode: types.IbanCheck, sent: "GB29NWBK60161331926819"},
+		{name: "check country without structure", country: "SE", iban: "se45 5000 0000 0583 9825 7466", mode: types.IbanCheck, sent: "SE4550000000058398257466"},
+		{name: "check invalid iban of country without structure", country: "SE", iban: "SE4550000000058398257467", mode: types.IbanCheck, err: accountclient.ErrValidation},
+		{name: "check mismatch", country: "GB", iban: "GB82WEST12345698765432", mode: types.IbanCheck, err: accountclient.ErrValidation},
+		{name: "check country mismatch", country: "IE", iban: "GB29NWBK60161331926819", mode: types.IbanCheck, err: accountclient.ErrValidation},
+		{name: "check no iban", country: "GB", mode: types.IbanCheck},
+		{name: "as is", country: "GB", iban: "GB00", mode: types.IbanAsIs, sent: "GB00"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var sent string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				req := &types.CreateAccountRequest{}
+				if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+					t.Errorf("unexpected error %v", err)
+				}
+				sent = req.Data.Attributes.Iban
+				w.WriteHeader(http.StatusCreated)
+				_, _ = w.Write([]byte(`{"data":{}}`))
+			}))
+			defer server.Close()
+
+			cli, err := accountclient.NewClientWithOptions(server.URL)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			country := tc.country
+			req := &types.CreateAccountRequest{
+				Data: &models.Account{
+					ID:             &accountID,
+					OrganisationID: &organisationID,
+					Attributes: &models.AccountAttributes{
+						Country:       &country,
+						BankID:        "601613",
+						BankIDCode:    "GBDSC",
+						Bic:           "NWBKGB22",
+						AccountNumber: "31926819",
+						Iban:          tc.iban,
+					},
+				},
+				Iban: tc.mode,
+			}
+			_, err = cli.CreateAccount(req)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("unexpected error %v ; expected %v", err, tc.err)
+			}
+			if sent != tc.sent {
+				t.Fatalf("wrong iban sent: want %q got %q", tc.sent, sent)
+			}
+			if req.Data.Attributes.Iban != tc.iban {
+				t.Fatalf("caller request modified")
+			}
+		})
+	}
+}